@@ -0,0 +1,71 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func (s *State) addBookmark(path string) bool {
+	if slices.Contains(s.Bookmarks, path) {
+		return false
+	}
+	s.Bookmarks = append(s.Bookmarks, path)
+	return true
+}
+
+func (s *State) removeBookmark(path string) {
+	s.Bookmarks = slices.DeleteFunc(s.Bookmarks, func(p string) bool { return p == path })
+}
+
+// newBookmarksView lists all bookmarks, Enter jumps to one and d removes it.
+// Bookmarks whose folder is gone from the tree are marked as broken.
+func newBookmarksView(conf *Config, root *Folder, state *State, jump func(*Folder)) *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(" bookmarks (enter = go, d = remove) ")
+
+	var render func()
+	render = func() {
+		cur := list.GetCurrentItem()
+		list.Clear()
+		for _, path := range state.Bookmarks {
+			folder, ok := FindByPath(root, path)
+			if !ok {
+				list.AddItem(conf.tag("red")+tview.Escape(path)+" (broken)", "", 0, nil)
+				continue
+			}
+			text := fmt.Sprintf("%s%8s %s%s", conf.tag("orange::b"), folder.sizeLabel(conf), conf.tag("blue::b"), tview.Escape(path))
+			list.AddItem(text, "", 0, func() {
+				jump(folder)
+			})
+		}
+		if list.GetItemCount() == 0 {
+			list.AddItem("no bookmarks yet, press B in a folder to add it", "", 0, nil)
+		}
+		list.SetCurrentItem(cur)
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() != 'd' && event.Key() != tcell.KeyDelete {
+			return event
+		}
+		i := list.GetCurrentItem()
+		if i >= len(state.Bookmarks) {
+			return nil
+		}
+		state.removeBookmark(state.Bookmarks[i])
+		if err := state.save(); err != nil {
+			log("failed to save state: "+err.Error(), ERROR)
+		}
+		render()
+		return nil
+	})
+
+	render()
+	return list
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// budget is the most a folder (with everything in it) may take up
+type budget struct {
+	Path string
+	Max  int64
+}
+
+// parseBudget reads a budget like /Photos=10gb
+func parseBudget(conf *Config, s string) (budget, error) {
+	path, size, ok := strings.Cut(s, "=")
+	if !ok || path == "" {
+		return budget{}, errors.New("invalid budget, expected a path and size like /Photos=10gb: " + s)
+	}
+	n, err := conf.sizeFromString(strings.TrimSpace(size))
+	if err != nil {
+		return budget{}, errors.New("invalid size in budget " + s + ": " + err.Error())
+	}
+	return budget{Path: path, Max: int64(n)}, nil
+}
+
+// checkBudgets compares the cached size of every budget's folder with its limit and
+// prints one line per budget. It returns how many were exceeded.
+// A path that isn't in the cache is an error, a typo shouldn't pass silently.
+func checkBudgets(conf *Config, root *Folder, budgets []budget, w io.Writer) (int, error) {
+	exceeded := 0
+	for _, b := range budgets {
+		f, ok := FindByPath(root, b.Path)
+		if !ok {
+			return exceeded, errors.New("budget path not found in the cache: " + b.Path)
+		}
+		status := "ok"
+		if f.size > b.Max {
+			status = "EXCEEDED"
+			exceeded++
+		}
+		if _, err := fmt.Fprintf(w, "%-8s %10s of %10s %5.1f%%  %s\n",
+			status, conf.formatSize(f.size), conf.formatSize(b.Max), float64(f.size)/float64(max(b.Max, 1))*100, f.fullPath()); err != nil {
+			return exceeded, err
+		}
+	}
+	return exceeded, nil
+}
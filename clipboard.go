@@ -0,0 +1,50 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// clipboardCommands are tried in order, the first one that is installed wins
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard hands the text to whatever clipboard tool the system has
+func copyToClipboard(text string) error {
+	for _, parts := range clipboardCommands {
+		if _, err := exec.LookPath(parts[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(parts[0], parts[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errors.New(parts[0] + " failed: " + strings.TrimSpace(string(out)+" "+err.Error()))
+		}
+		return nil
+	}
+	return errors.New("no clipboard tool found, install one of pbcopy, wl-copy, xclip or xsel")
+}
+
+// plainListing is what the list shows, line by line, without any colors or other tags
+func plainListing(list *tview.List) string {
+	var res strings.Builder
+	plain := tview.NewTextView().SetDynamicColors(true)
+	for i := 0; i < list.GetItemCount(); i++ {
+		text, _ := list.GetItemText(i)
+		plain.SetText(text)
+		res.WriteString(strings.TrimRight(plain.GetText(true), " "))
+		res.WriteString("\n")
+	}
+	return res.String()
+}
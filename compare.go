@@ -0,0 +1,97 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+type folderDiff struct {
+	path string
+	a, b int64
+	inA  bool
+	inB  bool
+}
+
+func (d folderDiff) delta() int64 {
+	if d.a > d.b {
+		return d.a - d.b
+	}
+	return d.b - d.a
+}
+
+// compareFolders matches folders of two trees by path and reports the ones whose size
+// differs or that only exist on one side. Below a folder that only exists on one side
+// nothing else is reported, it would only repeat the same difference.
+func compareFolders(conf *Config, a, b *Folder) []folderDiff {
+	res := []folderDiff{}
+	if a.size != b.size {
+		res = append(res, folderDiff{path: a.fullPath(), a: a.size, b: b.size, inA: true, inB: true})
+	}
+
+	for i := range a.Folders {
+		child := a.Folders[i]
+		if conf.Ignore.matches(child.fullPath(), true) {
+			continue
+		}
+		if other, ok := b.folderIdx[pathName(child.Name)]; ok {
+			res = append(res, compareFolders(conf, child, other)...)
+		} else {
+			res = append(res, folderDiff{path: child.fullPath(), a: child.size, inA: true})
+		}
+	}
+	for i := range b.Folders {
+		child := b.Folders[i]
+		if conf.Ignore.matches(child.fullPath(), true) {
+			continue
+		}
+		if _, ok := a.folderIdx[pathName(child.Name)]; !ok {
+			res = append(res, folderDiff{path: child.fullPath(), b: child.size, inB: true})
+		}
+	}
+	return res
+}
+
+// writeCompare prints the differences between two caches, biggest first
+func writeCompare(conf *Config, pathA, pathB string, w io.Writer) error {
+	trees := make([]*Folder, 2)
+	for i, path := range []string{pathA, pathB} {
+		c := *conf
+		c.SavePath = path
+		tree, err := load(&c)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		tree.path = "/"
+		trees[i] = tree
+	}
+
+	diffs := compareFolders(conf, trees[0], trees[1])
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].delta() != diffs[j].delta() {
+			return diffs[i].delta() > diffs[j].delta()
+		}
+		return diffs[i].path < diffs[j].path
+	})
+
+	side := func(size int64, ok bool) string {
+		if !ok {
+			return "-"
+		}
+		return conf.formatSize(size)
+	}
+	if _, err := fmt.Fprintf(w, "%10s %10s %11s  %s\n", truncate(filepath.Base(pathA), 10), truncate(filepath.Base(pathB), 10), "delta", "path"); err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		if _, err := fmt.Fprintf(w, "%10s %10s %+11s  %s\n", side(d.a, d.inA), side(d.b, d.inB), conf.formatDelta(d.b-d.a), d.path); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d folder(s) differ\n", len(diffs))
+	return err
+}
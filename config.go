@@ -0,0 +1,529 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Config holds everything that can be set for a run. It is populated from
+// flags in main and passed down to whoever needs it.
+type Config struct {
+	// where the cache is stored
+	SavePath string
+	// file permissions of the cache
+	CacheMode os.FileMode
+	// sort the cache by ID, so it only changes where the drive did, e.g. to keep it in git
+	StableCache bool
+	// print the fields of the cache and exit
+	DescribeCache bool
+	// delete the cache and exit
+	Reset bool
+	// don't ask before -reset deletes the cache
+	Force bool
+	// IDs of the folders or shared drives to start from, none for My Drive.
+	// With several, the root is made up and has them as its folders.
+	RootIDs []string
+	// what the cache is built for, RootIDs joined by commas or the Remote
+	RootID string
+	// who scans, stored with every folder that is fetched. Defaults to the OS user.
+	ScannerName string
+	// config dir gdrive is run with, empty for its default
+	GdriveConfig string
+	// list folders with gdrive's --json instead of its delimited output, for versions that have it
+	GdriveJSON bool
+	// rclone remote to scan instead of Google Drive via gdrive, e.g. gdrive: or s3:bucket/
+	Remote string
+	// only list files owned by the current user, i.e. that count against the quota
+	OwnedOnly bool
+	// include the size the API reports for Google Docs, which normally don't count against the quota
+	CountDocs bool
+	// give Google Docs a rough size of what they'd take up when exported, instead of 0
+	EstimateDocSize bool
+	// estimated size by kind of Google Doc (document, spreadsheet, presentation, ...)
+	DocEstimates map[string]int64
+	// folders whose data is older than this are re-fetched
+	MaxAge time.Duration
+	// overrides MaxAge by folder depth, starting at the root. The last one applies to everything deeper.
+	MaxAgeByDepth []time.Duration
+	// deep scans stop after this long, 0 for never
+	ScanTimeout time.Duration
+	// re-fetch one stale folder this often while the TUI is open, 0 to never do it
+	AutoRefresh time.Duration
+	// how the TUI starts: list shows one folder at a time, tree the expandable tree (V toggles)
+	View string
+	// default sort order of the explorer: size, name, or date
+	Sort string
+	// only list this many of the largest entries in a folder, 0 for all
+	MaxItems int
+	// entries below this percentage of their folder are collapsed into one row, 0 to show all
+	OtherThreshold float64
+	// list folders before files, instead of mixing them by the sort order
+	DirsFirst bool
+	// total drive quota in bytes, 0 if unknown
+	Quota int64
+	// entries of at least this many bytes are highlighted, 0 for none
+	Highlight int64
+	// show how many folders below have stale data next to a folder: direct (subfolders), total (all), empty for no
+	StaleBadge string
+	// serve the cached tree via HTTP on this address instead of starting the TUI
+	Serve string
+	// print the cached folders as an indented tree instead of starting the TUI
+	Tree bool
+	// print the root's direct children with their share of it instead of starting the TUI
+	Summary bool
+	// parse this file as the output of gdrive files list and print it, instead of starting the TUI
+	FromFile string
+	// paths of two caches to compare instead of starting the TUI
+	Compare []string
+	// check folders against their size budgets instead of starting the TUI, failing if any is exceeded
+	Budgets []budget
+	// re-fetch folders and compare their sizes with the cache instead of starting the TUI
+	Verify bool
+	// number of random folders to verify, 0 for all
+	VerifySample int
+	// export the cached tree in this format (svg, csv, json) instead of starting the TUI
+	Export string
+	// only export the largest N files (csv, json)
+	Top int
+	// file that exports are written to, stdout if empty
+	Output string
+	// how many levels of folders are drawn in exports
+	Depth int
+	// sizes are in decimal units (1kb = 1000 bytes) instead of binary ones (1024 bytes)
+	SI bool
+	// render without colors, also set by the NO_COLOR environment variable
+	NoColor bool
+	// don't react to the mouse, so the terminal's own selection works for copy & paste
+	NoMouse bool
+	// how progress bars look (blocks, ascii, dots, arrows), empty for the last one used or blocks
+	BarStyle string
+	// delete marked entries right away, without asking first
+	NoConfirm bool
+	// where files are downloaded to with g
+	DownloadDir string
+	// write scan progress as JSON lines to this file, - for stdout
+	ProgressJSON string
+	// experimental: where deep scans keep the files of folders instead of memory, jsonl or empty for memory
+	StreamTo string
+	// only log messages at or above this level
+	LogLevel LOG_LEVEL
+	// only log errors, e.g. so scripts get nothing but the export
+	Quiet bool
+	// entries that are skipped in scans and hidden in the explorer, from -ignore-file and -exclude-path
+	Ignore ignoreList
+	// only count files at most / at least this old, 0 for any age
+	NewerThan time.Duration
+	OlderThan time.Duration
+	// gdrive types (e.g. document, shortcut) that are left out of scans, only their count is kept
+	ExcludeTypes []string
+
+	asciiBar bool    // -ascii-bar, which unlike -bar-style isn't remembered
+	tooOld   []int64 // unix time before which data counts as stale, by folder depth
+	newestAt int64   // unix time files have to be created before, 0 for any
+	oldestAt int64   // unix time files have to be created after, 0 for any
+}
+
+func parseConfig(args []string) (*Config, error) {
+	conf := Config{}
+	var docEstimates, maxAgeByDepth, newerThan, olderThan, ignoreFile, quota, highlight, logLevel, excludeTypes, cacheMode string
+
+	flags := flag.NewFlagSet("ggdu", flag.ContinueOnError)
+	flags.StringVar(&conf.SavePath, "cache", "", "path of the cache file (default: ggdu/db.json in the user cache dir)")
+	flags.StringVar(&cacheMode, "cache-mode", "0644", "file permissions of the cache in octal, e.g. 0600 to keep it private")
+	flags.BoolVar(&conf.StableCache, "stable-cache", false, "save the cache sorted by ID, so unchanged data gives identical files")
+	flags.BoolVar(&conf.DescribeCache, "describe-cache", false, "print the JSON fields of the cache, for writing tools against it, and exit")
+	flags.BoolVar(&conf.Reset, "reset", false, "delete the cache (after asking) and exit")
+	flags.BoolVar(&conf.Force, "force", false, "don't ask before -reset deletes the cache")
+	flags.Func("root-id", "ID of the folder or shared drive to start from, repeat it to see several side by side (default: My Drive)", func(s string) error {
+		conf.RootIDs = append(conf.RootIDs, s)
+		return nil
+	})
+	flags.StringVar(&conf.ScannerName, "scanner-name", "", "name stored with the folders you scan, for caches shared in a team (default: your OS user)")
+	flags.BoolVar(&conf.GdriveJSON, "gdrive-json", false, "list folders with gdrive files list --json, if your gdrive version supports it")
+	flags.StringVar(&conf.GdriveConfig, "gdrive-config", "", "config dir to run gdrive with (gdrive --config), e.g. to use several accounts")
+	flags.StringVar(&conf.Remote, "remote", "", "scan this rclone remote instead of Google Drive via gdrive, e.g. gdrive: or s3:bucket/")
+	flags.BoolVar(&conf.OwnedOnly, "owned-only", false, "only include files I own, skipping ones shared with me")
+	flags.BoolVar(&conf.CountDocs, "count-docs", false, "include the reported size of Google Docs in totals")
+	flags.BoolVar(&conf.EstimateDocSize, "estimate-doc-size", false, "count Google Docs with a rough estimate of their exported size (see -doc-estimates)")
+	flags.StringVar(&docEstimates, "doc-estimates", "document=100kb,spreadsheet=500kb,presentation=2mb", "estimated size by kind of Google Doc for -estimate-doc-size, document is used when the kind is unknown")
+	flags.DurationVar(&conf.MaxAge, "max-age", 24*time.Hour, "re-fetch folders whose data is older than this")
+	flags.StringVar(&maxAgeByDepth, "max-age-by-depth", "", "comma-separated max age per folder level from the root, the last one for all deeper levels (e.g. 7d,30d)")
+	flags.DurationVar(&conf.ScanTimeout, "scan-timeout", 0, "stop scanning everything in a folder (x) after this long and show what there is so far (e.g. 5m)")
+	flags.DurationVar(&conf.AutoRefresh, "auto-refresh", 0, "while the TUI is open, re-fetch one stale folder this often (e.g. 30s)")
+	flags.StringVar(&conf.View, "view", "list", "how the TUI starts: list (one folder at a time) or tree (expandable folders)")
+	flags.StringVar(&conf.Sort, "sort", "size", "default sort order: size, name, or date")
+	flags.IntVar(&conf.MaxItems, "max-items", 0, "only list the N largest entries of a folder, to keep huge folders responsive")
+	flags.Float64Var(&conf.OtherThreshold, "other-threshold", 0, "collapse entries below this percentage of their folder into one row (o toggles, default 1 then)")
+	flags.BoolVar(&conf.DirsFirst, "dirs-first", true, "list folders before files (-dirs-first=false mixes them)")
+	flags.StringVar(&quota, "quota", "", "total drive quota (e.g. 100gb) to show sizes as a share of it")
+	flags.StringVar(&conf.StaleBadge, "stale-badge", "", "show how many folders below a folder are stale: direct (its subfolders) or total (all of them)")
+	flags.StringVar(&highlight, "highlight", "", "highlight entries of at least this size (e.g. 1gb)")
+	flags.StringVar(&conf.Serve, "serve", "", "serve the cached tree as a web UI on this address (e.g. :8080)")
+	flags.BoolVar(&conf.Tree, "tree", false, "print the cached folders as a tree and exit")
+	flags.BoolVar(&conf.Summary, "summary", false, "print the size of everything at the top level and exit")
+	flags.StringVar(&conf.FromFile, "from-file", "", "parse saved output of gdrive files list, print it like -summary and exit")
+	compare := flags.Bool("compare", false, "compare the two caches given as arguments (e.g. of two accounts) and exit")
+	budgets := []string{}
+	flags.Func("budget", "fail if a folder is larger than its budget, e.g. /Photos=10gb (repeatable), instead of starting the TUI", func(s string) error {
+		budgets = append(budgets, s)
+		return nil
+	})
+	flags.BoolVar(&conf.Verify, "verify", false, "re-fetch folders and report where cached sizes are off, then exit")
+	flags.IntVar(&conf.VerifySample, "verify-sample", 0, "only verify this many random folders (default: all)")
+	flags.StringVar(&conf.Export, "export", "", "export the cached tree instead of starting the TUI (svg, csv, json)")
+	flags.IntVar(&conf.Top, "top", 0, "only export the N largest files (csv, json)")
+	flags.StringVar(&conf.Output, "output", "", "file to write exports to (default: stdout)")
+	flags.IntVar(&conf.Depth, "depth", 3, "number of folder levels drawn in exports")
+	flags.BoolVar(&conf.SI, "si", false, "use decimal units (1kb = 1000 bytes) to read and show sizes, like the backend may report them (default 1024)")
+	flags.BoolVar(&conf.NoColor, "no-color", os.Getenv("NO_COLOR") != "", "render without colors (also set via NO_COLOR)")
+	flags.BoolVar(&conf.NoMouse, "no-mouse", false, "disable mouse support, e.g. to select text for copy & paste")
+	flags.StringVar(&conf.BarStyle, "bar-style", "", "look of progress bars: blocks, ascii, dots or arrows, remembered for next time (default blocks)")
+	flags.BoolVar(&conf.asciiBar, "ascii-bar", false, "draw progress bars with # only, for fonts without block characters (like -bar-style ascii, but only for this run)")
+	flags.BoolVar(&conf.NoConfirm, "no-confirm", false, "DANGEROUS: delete marked entries without asking for confirmation")
+	flags.StringVar(&conf.DownloadDir, "download-dir", ".", "folder that g downloads the selected file to")
+	flags.StringVar(&conf.ProgressJSON, "progress-json", "", "write scan progress as JSON lines to this file (- for stdout, not with the TUI)")
+	flags.StringVar(&conf.StreamTo, "stream-to", "", "experimental: keep the files of deep scans on disk next to the cache instead of in memory, for huge drives: jsonl")
+	flags.StringVar(&logLevel, "log-level", "info", "minimum level of log messages: debug, info, warn, or error")
+	flags.BoolVar(&conf.Quiet, "quiet", false, "only log errors, whatever -log-level says")
+	flags.StringVar(&ignoreFile, "ignore-file", "", "file with glob patterns of folders/files to skip in scans and hide")
+	excludePaths := []string{}
+	flags.Func("exclude-path", "full path of a folder to skip in scans and hide, e.g. /Backups (repeatable)", func(s string) error {
+		excludePaths = append(excludePaths, s)
+		return nil
+	})
+	flags.StringVar(&newerThan, "newer-than", "", "only count files created within this time, e.g. 30d (units: s, m, h, d, w, y)")
+	flags.StringVar(&olderThan, "older-than", "", "only count files created longer ago than this, e.g. 1y")
+	flags.StringVar(&excludeTypes, "exclude-type", "", "comma-separated gdrive types to skip in scans (e.g. document,shortcut)")
+	// the flag package reports its own errors, everything after we report the same way
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	fail := func(err error) (*Config, error) {
+		fmt.Fprintln(flags.Output(), err)
+		return nil, err
+	}
+
+	for _, s := range budgets {
+		b, err := parseBudget(&conf, s)
+		if err != nil {
+			return fail(err)
+		}
+		conf.Budgets = append(conf.Budgets, b)
+	}
+
+	if *compare {
+		if flags.NArg() != 2 {
+			return fail(errors.New("-compare needs two caches, e.g. -compare personal.json work.json"))
+		}
+		conf.Compare = flags.Args()
+	}
+
+	if conf.EstimateDocSize && conf.CountDocs {
+		return fail(errors.New("-estimate-doc-size and -count-docs can't be combined, pick one size for Google Docs"))
+	}
+	conf.DocEstimates = map[string]int64{}
+	for _, s := range strings.Split(docEstimates, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		kind, size, ok := strings.Cut(s, "=")
+		n, err := conf.sizeFromString(strings.TrimSpace(size))
+		if !ok || err != nil {
+			return fail(errors.New("invalid doc estimate, expected a kind and size like spreadsheet=500kb: " + s))
+		}
+		conf.DocEstimates[strings.TrimSpace(kind)] = int64(n)
+	}
+
+	if _, ok := barStyles[conf.BarStyle]; conf.BarStyle != "" && !ok {
+		return fail(errors.New("unsupported bar style: " + conf.BarStyle + ", expected blocks, ascii, dots or arrows"))
+	}
+
+	if conf.StreamTo != "" && conf.StreamTo != "jsonl" {
+		return fail(errors.New("unsupported -stream-to: " + conf.StreamTo + ", only jsonl is supported so far"))
+	}
+
+	if conf.StaleBadge != "" && conf.StaleBadge != "direct" && conf.StaleBadge != "total" {
+		return fail(errors.New("unsupported -stale-badge: " + conf.StaleBadge + ", expected direct or total"))
+	}
+
+	if conf.View != "list" && conf.View != "tree" {
+		return fail(errors.New("unsupported view: " + conf.View + ", expected list or tree"))
+	}
+
+	if !slices.Contains(sortOrders, conf.Sort) {
+		return fail(errors.New("unsupported sort order: " + conf.Sort))
+	}
+
+	var err error
+	if conf.SavePath == "" {
+		if conf.SavePath, err = defaultSavePath(); err != nil {
+			return fail(err)
+		}
+	}
+
+	mode, err := strconv.ParseUint(cacheMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return fail(errors.New("invalid -cache-mode, expected octal permissions like 0600: " + cacheMode))
+	}
+	conf.CacheMode = os.FileMode(mode)
+
+	if conf.LogLevel, err = parseLogLevel(logLevel); err != nil {
+		return fail(err)
+	}
+	if conf.Quiet {
+		conf.LogLevel = ERROR
+	}
+
+	if conf.Top > 0 && conf.Export != "csv" && conf.Export != "json" {
+		return fail(errors.New("-top needs -export csv or -export json"))
+	}
+
+	if quota != "" {
+		n, err := conf.sizeFromString(quota)
+		if err != nil {
+			return fail(err)
+		}
+		conf.Quota = int64(n)
+	}
+
+	if highlight != "" {
+		n, err := conf.sizeFromString(highlight)
+		if err != nil {
+			return fail(err)
+		}
+		conf.Highlight = int64(n)
+	}
+
+	if ignoreFile != "" {
+		conf.Ignore, err = loadIgnoreFile(ignoreFile)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	for _, path := range excludePaths {
+		p, err := exactPathPattern(path)
+		if err != nil {
+			return fail(err)
+		}
+		conf.Ignore = append(conf.Ignore, p)
+	}
+
+	for _, t := range strings.Split(excludeTypes, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if t == "folder" {
+			return fail(errors.New("folders can't be excluded by type, use -ignore-file instead"))
+		}
+		conf.ExcludeTypes = append(conf.ExcludeTypes, t)
+	}
+
+	conf.RootID = strings.Join(conf.RootIDs, ",")
+	if conf.ScannerName == "" {
+		if u, err := user.Current(); err == nil {
+			conf.ScannerName = u.Username
+		}
+	}
+
+	if conf.GdriveConfig != "" {
+		if info, err := os.Stat(conf.GdriveConfig); err != nil || !info.IsDir() {
+			return fail(errors.New("-gdrive-config is not a directory: " + conf.GdriveConfig))
+		}
+	}
+	if conf.Remote != "" {
+		if len(conf.RootIDs) > 0 || conf.OwnedOnly {
+			return fail(errors.New("-remote can't be combined with -root-id or -owned-only, they are gdrive only"))
+		}
+		// just a name like gdrive would be a local path for rclone
+		if !strings.Contains(conf.Remote, ":") {
+			conf.Remote += ":"
+		}
+		conf.RootID = conf.Remote
+	}
+
+	for _, s := range strings.Split(maxAgeByDepth, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		age, err := parseAge(s)
+		if err != nil {
+			return fail(err)
+		}
+		conf.MaxAgeByDepth = append(conf.MaxAgeByDepth, age)
+	}
+
+	if conf.NewerThan, err = parseAge(newerThan); err != nil {
+		return fail(err)
+	}
+	if conf.OlderThan, err = parseAge(olderThan); err != nil {
+		return fail(err)
+	}
+
+	if conf.ProgressJSON == "-" && conf.interactive() {
+		return fail(errors.New("-progress-json - would write into the TUI, use a file or a mode without it like -verify"))
+	}
+
+	now := time.Now()
+	if conf.NewerThan > 0 {
+		conf.oldestAt = now.Add(-conf.NewerThan).Unix()
+	}
+	if conf.OlderThan > 0 {
+		conf.newestAt = now.Add(-conf.OlderThan).Unix()
+	}
+	conf.updateTooOld(now)
+	return &conf, nil
+}
+
+// interactive reports if the run ends up in the TUI, i.e. none of the modes that print something and exit was chosen
+func (c *Config) interactive() bool {
+	return !c.DescribeCache && !c.Reset && len(c.Compare) == 0 && c.FromFile == "" && !c.Verify && len(c.Budgets) == 0 &&
+		!c.Tree && !c.Summary && c.Export == "" && c.Serve == ""
+}
+
+// updateTooOld moves the point before which data counts as stale, e.g. while the TUI stays open
+func (c *Config) updateTooOld(now time.Time) {
+	ages := c.MaxAgeByDepth
+	if len(ages) == 0 {
+		ages = []time.Duration{c.MaxAge}
+	}
+	c.tooOld = make([]int64, len(ages))
+	for i := range ages {
+		c.tooOld[i] = now.Add(-ages[i]).Unix()
+	}
+}
+
+// tooOldFor is the unix time before which the folder's data counts as stale, which depends on how deep it is
+func (c *Config) tooOldFor(f *Folder) int64 {
+	return c.tooOld[min(f.depth(), len(c.tooOld)-1)]
+}
+
+// parseAge reads a duration like time.ParseDuration, plus days (d), weeks (w) and years (y).
+// Empty is 0.
+func parseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour, "y": 365 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, errors.New("invalid age: " + s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.New("invalid age: " + s)
+	}
+	return d, nil
+}
+
+// inDateRange reports if a file created at this unix time counts with -newer-than/-older-than.
+// Files without a date never do if any of them is set.
+func (c *Config) inDateRange(date int64) bool {
+	if c.oldestAt == 0 && c.newestAt == 0 {
+		return true
+	}
+	if date == 0 {
+		return false
+	}
+	return (c.oldestAt == 0 || date >= c.oldestAt) && (c.newestAt == 0 || date <= c.newestAt)
+}
+
+// hidesFile reports if a file is left out of views and exports, it's ignored or outside of the date range
+func (c *Config) hidesFile(path string, file *File) bool {
+	return c.Ignore.matches(path, false) || !c.inDateRange(file.Date)
+}
+
+// gdrive builds a gdrive command with the given arguments, using the -gdrive-config if there is one
+func (c *Config) gdrive(args ...string) []string {
+	res := []string{"gdrive"}
+	if c.GdriveConfig != "" {
+		res = append(res, "--config", c.GdriveConfig)
+	}
+	return append(res, args...)
+}
+
+// gdriveOnly fails for what only works with gdrive, e.g. deleting, if -remote is used instead
+func (c *Config) gdriveOnly(what string) error {
+	if c.Remote != "" {
+		return errors.New(what + " only works with gdrive, not with -remote " + c.Remote)
+	}
+	return nil
+}
+
+// docEstimate is the guessed size of a Google Doc for -estimate-doc-size. Its kind comes
+// from the MIME type where the backend reports one, everything else counts as a document.
+func (c *Config) docEstimate(file *File) int64 {
+	if kind, ok := strings.CutPrefix(file.MimeType, "application/vnd.google-apps."); ok {
+		if size, ok := c.DocEstimates[kind]; ok {
+			return size
+		}
+	}
+	return c.DocEstimates["document"]
+}
+
+// tag returns a tview style tag like [orange::b], without the colors if they are disabled
+func (c *Config) tag(t string) string {
+	if !c.NoColor {
+		return "[" + t + "]"
+	}
+	if _, attrs, ok := strings.Cut(t, ":"); ok {
+		return "[:" + attrs + "]"
+	}
+	return ""
+}
+
+// color returns c, or the terminal's default if colors are disabled
+func (c *Config) color(color tcell.Color) tcell.Color {
+	if c.NoColor {
+		return tcell.ColorDefault
+	}
+	return color
+}
+
+func (c *Config) barRunes() []rune {
+	if c.asciiBar {
+		return barStyles["ascii"]
+	}
+	if runes, ok := barStyles[c.BarStyle]; ok {
+		return runes
+	}
+	return progressRunes
+}
+
+// defaultSavePath keeps the cache in the user's cache dir, unless there is still
+// a db.json in the working directory from older versions
+func defaultSavePath() (string, error) {
+	const legacy = "db.json"
+	if fileExists(legacy) {
+		return legacy, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return legacy, nil
+	}
+	dir = filepath.Join(dir, "ggdu")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.New("failed to create cache dir: " + err.Error())
+	}
+	return filepath.Join(dir, "db.json"), nil
+}
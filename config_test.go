@@ -0,0 +1,91 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// parseTestConfig is parseConfig with the cache in a temp dir
+func parseTestConfig(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	return parseConfig(append([]string{"-cache", filepath.Join(t.TempDir(), "db.json")}, args...))
+}
+
+func TestProgressJSONToStdout(t *testing.T) {
+	if _, err := parseTestConfig(t, "-progress-json", "-"); err == nil {
+		t.Error("progress on stdout must not be allowed with the TUI")
+	}
+	if _, err := parseTestConfig(t, "-progress-json", "-", "-verify"); err != nil {
+		t.Errorf("progress on stdout should be fine without the TUI: %v", err)
+	}
+	if _, err := parseTestConfig(t, "-progress-json", filepath.Join(t.TempDir(), "progress.json")); err != nil {
+		t.Errorf("progress in a file should be fine with the TUI: %v", err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"30d", 30 * day},
+		{"1.5d", 36 * time.Hour},
+		{"2w", 14 * day},
+		{"1y", 365 * day},
+		{"12h", 12 * time.Hour},
+		{"90m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"d", "30", "30x", "a year", "1y2d"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q) should fail", in)
+		}
+	}
+}
+
+func TestInDateRange(t *testing.T) {
+	now := time.Now()
+	ago := func(days int) int64 { return now.AddDate(0, 0, -days).Unix() }
+	tests := []struct {
+		args []string
+		date int64
+		want bool
+	}{
+		{nil, ago(400), true},
+		{nil, 0, true},
+		{[]string{"-newer-than", "30d"}, ago(10), true},
+		{[]string{"-newer-than", "30d"}, ago(40), false},
+		{[]string{"-older-than", "1y"}, ago(400), true},
+		{[]string{"-older-than", "1y"}, ago(10), false},
+		{[]string{"-newer-than", "30d", "-older-than", "7d"}, ago(10), true},
+		{[]string{"-newer-than", "30d", "-older-than", "7d"}, ago(1), false},
+		{[]string{"-newer-than", "30d", "-older-than", "7d"}, ago(40), false},
+		// without a date, a file can't be in any range
+		{[]string{"-newer-than", "30d"}, 0, false},
+	}
+	for _, tt := range tests {
+		conf, err := parseTestConfig(t, tt.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := conf.inDateRange(tt.date); got != tt.want {
+			t.Errorf("%v: inDateRange of %s = %v, want %v", tt.args, time.Unix(tt.date, 0).Format(time.DateOnly), got, tt.want)
+		}
+	}
+
+	if _, err := parseTestConfig(t, "-newer-than", "soon"); err == nil {
+		t.Error("an invalid age should fail")
+	}
+}
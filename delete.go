@@ -0,0 +1,166 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// marks are the entries selected for a batch operation, by their ID.
+// They may come from different folders, so each one remembers where it lives.
+type marks map[string]mark
+
+type mark struct {
+	parent *Folder
+	entry  entry
+}
+
+// toggle marks the entry, or unmarks it if it already was. The .. entry can't be marked.
+func (m marks) toggle(parent *Folder, e entry) {
+	id := e.id()
+	if id == "" {
+		return
+	}
+	if _, ok := m[id]; ok {
+		delete(m, id)
+		return
+	}
+	m[id] = mark{parent: parent, entry: e}
+}
+
+// path is the full path of the marked entry
+func (m mark) path() string {
+	if m.entry.folder != nil {
+		return m.entry.folder.fullPath()
+	}
+	return m.parent.filePath(m.entry.file)
+}
+
+func (m marks) has(e entry) bool {
+	_, ok := m[e.id()]
+	return ok
+}
+
+// below reports if the marked entry is somewhere inside the folder
+func (m mark) below(f *Folder) bool {
+	return m.parent.within(f)
+}
+
+// outermost leaves out marks inside marked folders, they go with the folder anyway
+func (m marks) outermost() marks {
+	res := marks{}
+	for id, mark := range m {
+		nested := false
+		for _, other := range m {
+			if other.entry.folder != nil && mark.below(other.entry.folder) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			res[id] = mark
+		}
+	}
+	return res
+}
+
+// size is the combined size of all marked entries, without estimates, counting everything only once
+func (m marks) size() int64 {
+	var res int64
+	for _, mark := range m.outermost() {
+		res += mark.entry.realSize()
+	}
+	return res
+}
+
+// deleteEntry permanently deletes a file or folder (with everything in it) on the backend
+func deleteEntry(conf *Config, e entry) error {
+	if err := conf.gdriveOnly("deleting"); err != nil {
+		return err
+	}
+	cmd := conf.gdrive("files", "delete")
+	if e.folder != nil {
+		cmd = append(cmd, "--recursive")
+	}
+	if _, err := sh(append(cmd, e.id())...); err != nil {
+		return errors.New("failed to delete " + e.name() + ": " + err.Error())
+	}
+	return nil
+}
+
+// remove drops the child with the given ID from the folder, without any backend calls
+func (f *Folder) remove(id string) {
+	f.Folders = slices.DeleteFunc(f.Folders, func(folder *Folder) bool { return folder.ID == id })
+	f.Files = slices.DeleteFunc(f.Files, func(file *File) bool { return file.ID == id })
+	if store := f.store(); f.Streamed && store != nil {
+		files, err := store.get(f.ID)
+		if err != nil {
+			log("failed to read the files of "+f.fullPath()+": "+err.Error(), ERROR)
+		}
+		if left := slices.DeleteFunc(files, func(file *File) bool { return file.ID == id }); len(left) != len(files) {
+			if err := store.put(f.ID, left); err != nil {
+				log("failed to remove "+id+" from the stream store: "+err.Error(), ERROR)
+			}
+			f.own = nil
+		}
+	}
+	f.markDirty()
+}
+
+// deleteMarked deletes all marked entries one by one on the backend and returns the IDs of
+// those that are gone. Marks inside a marked folder are gone with it and aren't deleted on their own.
+// It stops at the first failure, everything deleted until then stays deleted.
+// The tree isn't touched, that's up to removeDeleted, so m can be a snapshot taken for a background goroutine.
+func deleteMarked(conf *Config, m marks) ([]string, error) {
+	deleted := []string{}
+	for id, mark := range m.outermost() {
+		if err := deleteEntry(conf, mark.entry); err != nil {
+			return deleted, err
+		}
+		log("deleted "+mark.entry.name(), INFO)
+		d := deletion{ID: id, Name: mark.entry.name(), Path: mark.path(), Size: mark.entry.realSize(), Time: time.Now().Unix()}
+		if logErr := logDeletion(d); logErr != nil {
+			log("failed to add to the deletion log: "+logErr.Error(), WARN)
+		}
+		deleted = append(deleted, id)
+		if folder := mark.entry.folder; folder != nil {
+			for nestedID, nested := range m {
+				if nested.below(folder) {
+					deleted = append(deleted, nestedID)
+				}
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// removeDeleted takes what deleteMarked deleted out of the tree and unmarks it.
+// Sizes are recomputed and the cache is saved once for all of them.
+func removeDeleted(conf *Config, root *Folder, m marks, deleted []string) error {
+	for _, id := range deleted {
+		if mark, ok := m[id]; ok {
+			mark.parent.remove(id)
+			delete(m, id)
+		}
+	}
+
+	root.rebuild(conf)
+	if err := root.save(); err != nil {
+		return errors.New("failed to save cache: " + err.Error())
+	}
+	return nil
+}
+
+// attached is the folder itself, or the nearest of its parents that is still in the tree after a deletion
+func (f *Folder) attached() *Folder {
+	res := f
+	for cur := f; cur.parent != nil; cur = cur.parent {
+		if !slices.Contains(cur.parent.Folders, cur) {
+			res = cur.parent
+		}
+	}
+	return res
+}
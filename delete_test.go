@@ -0,0 +1,103 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDeleteMarked(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// deleting anything but "bad" works
+	fakeCommand(t, "gdrive", "for last; do :; done\n[ \"$last\" != bad ]\n")
+	conf := testConfig(t)
+	a := &File{ID: "a", Name: "a.txt", Size: 10}
+	b := &File{ID: "b", Name: "b.txt", Size: 20}
+	bad := &File{ID: "bad", Name: "bad.txt", Size: 40}
+	sub := &Folder{ID: "sub", Name: "sub", Files: []*File{a, b, bad}}
+	root := testTree(conf, &Folder{Folders: []*Folder{sub}})
+
+	marked := marks{}
+	marked.toggle(sub, entry{file: a})
+	marked.toggle(sub, entry{file: b})
+	deleted, err := deleteMarked(conf, maps.Clone(marked))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(deleted)
+	if !slices.Equal(deleted, []string{"a", "b"}) {
+		t.Errorf("expected a and b to be deleted, got %v", deleted)
+	}
+	if len(marked) != 2 || len(sub.Files) != 3 {
+		t.Errorf("deleteMarked must not touch the marks or the tree")
+	}
+
+	if err := removeDeleted(conf, root, marked, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if len(marked) != 0 || len(sub.Files) != 1 || root.size != 40 {
+		t.Errorf("expected only bad.txt to be left and nothing marked, got %d files, %d marks, size %d",
+			len(sub.Files), len(marked), root.size)
+	}
+
+	marked.toggle(sub, entry{file: bad})
+	deleted, err = deleteMarked(conf, maps.Clone(marked))
+	if err == nil || len(deleted) != 0 {
+		t.Errorf("expected the failed deletion to be reported, got %v, %v", deleted, err)
+	}
+	if err := removeDeleted(conf, root, marked, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if len(marked) != 1 || len(sub.Files) != 1 {
+		t.Errorf("what failed to delete has to stay in the tree and marked")
+	}
+}
+
+func TestDeleteNestedMarks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// a already went with its folder, deleting it on its own fails like it would with gdrive
+	fakeCommand(t, "gdrive", "for last; do :; done\n[ \"$last\" != a ]\n")
+	conf := testConfig(t)
+	a := &File{ID: "a", Name: "a.txt", Size: 10}
+	deeper := &Folder{ID: "deeper", Name: "deeper", Files: []*File{{ID: "d", Name: "d.txt", Size: 20}}}
+	sub := &Folder{ID: "sub", Name: "sub", Files: []*File{a}, Folders: []*Folder{deeper}}
+	c := &File{ID: "c", Name: "c.txt", Size: 40}
+	root := testTree(conf, &Folder{Folders: []*Folder{sub}, Files: []*File{c}})
+
+	marked := marks{}
+	marked.toggle(sub, entry{file: a})
+	marked.toggle(sub, entry{folder: deeper})
+	marked.toggle(root, entry{folder: sub})
+	marked.toggle(root, entry{file: c})
+	if got := marked.size(); got != 70 {
+		t.Errorf("marks inside a marked folder must only count once, expected 70b, got %d", got)
+	}
+	if got := slices.Sorted(maps.Keys(marked.outermost())); !slices.Equal(got, []string{"c", "sub"}) {
+		t.Errorf("expected only c and sub to be outermost, got %v", got)
+	}
+
+	for range 10 {
+		deleted, err := deleteMarked(conf, marked)
+		if err != nil {
+			t.Fatalf("nested marks must not be deleted on their own: %v", err)
+		}
+		slices.Sort(deleted)
+		if !slices.Equal(deleted, []string{"a", "c", "deeper", "sub"}) {
+			t.Errorf("expected everything to be gone, got %v", deleted)
+		}
+	}
+
+	deleted, _ := deleteMarked(conf, marked)
+	if err := removeDeleted(conf, root, marked, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if len(marked) != 0 || len(root.Folders) != 0 || len(root.Files) != 0 || root.size != 0 {
+		t.Errorf("expected nothing to be left and nothing marked, got %d marks and %d bytes", len(marked), root.size)
+	}
+	if deeper.attached() != root || sub.attached() != root || root.attached() != root {
+		t.Errorf("deleted folders should fall back to the root")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// deletion is one line in the deletion log, which keeps track of everything deleted through ggdu
+type deletion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Time int64  `json:"time"` // unix
+}
+
+func deletionLogPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ggdu", "deletions.log"), nil
+}
+
+func logDeletion(d deletion) error {
+	path, err := deletionLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recentDeletions reads the last n deletions from the log, newest first
+func recentDeletions(n int) ([]deletion, error) {
+	path, err := deletionLogPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res := []deletion{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var d deletion
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			log("skipping broken line in deletion log: "+scanner.Text(), WARN)
+			continue
+		}
+		res = append(res, d)
+	}
+	if len(res) > n {
+		res = res[len(res)-n:]
+	}
+	slices.Reverse(res)
+	return res, scanner.Err()
+}
+
+func newDeletionsView(conf *Config) *tview.TextView {
+	var sb strings.Builder
+	deletions, err := recentDeletions(recentCount)
+	if err != nil {
+		sb.WriteString("failed to read the deletion log: " + err.Error() + "\n")
+	}
+	for _, d := range deletions {
+		fmt.Fprintf(&sb, "%-16s %9s  %s\n", formatDate(d.Time), conf.formatSize(d.Size), tview.Escape(d.Path))
+	}
+	if len(deletions) == 0 && err == nil {
+		sb.WriteString("nothing deleted through ggdu yet\n")
+	}
+
+	view := tview.NewTextView().SetText(sb.String())
+	title := fmt.Sprintf(" %d most recent deletions ", len(deletions))
+	if path, err := deletionLogPath(); err == nil {
+		title += "(" + tview.Escape(path) + ") "
+	}
+	view.SetBorder(true).SetTitle(title)
+	return view
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// describeCache prints the JSON fields of the cache, straight from the types that are saved,
+// so it can't get out of date like documentation would
+func describeCache(w io.Writer) error {
+	fmt.Fprintf(w, "The cache is one JSON object, the root Folder. This is schema version %d, it is in the\n", cacheVersion)
+	fmt.Fprintln(w, "root's Version (none means 1). Newer versions are refused, unknown fields are ignored.")
+	fmt.Fprintln(w, "Sizes are in bytes, dates in unix seconds.")
+	for _, t := range []reflect.Type{reflect.TypeFor[Folder](), reflect.TypeFor[File]()} {
+		fmt.Fprintf(w, "\n%s\n", t.Name())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			optional := ""
+			if strings.Contains(opts, "omitempty") {
+				optional = " (optional)"
+			}
+			if _, err := fmt.Fprintf(w, "  %-12s %s%s\n", name, jsonType(field.Type), optional); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON type a Go type is encoded as
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	case reflect.Struct:
+		return t.Name()
+	case reflect.Slice:
+		return "array of " + jsonType(t.Elem())
+	case reflect.Map:
+		return "object of " + jsonType(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Float64:
+		return "number"
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type snapshotEntry struct {
+	name   string
+	size   int64
+	folder bool
+}
+
+// snapshot remembers the direct children of a folder by ID, to compare them after a refresh
+func (f *Folder) snapshot(conf *Config) map[string]snapshotEntry {
+	files := f.fileList(conf)
+	res := make(map[string]snapshotEntry, len(f.Folders)+len(files))
+	for i := range f.Folders {
+		folder := f.Folders[i]
+		res[folder.ID] = snapshotEntry{name: folder.Name + "/", size: folder.size, folder: true}
+	}
+	for i := range files {
+		file := files[i]
+		res[file.ID] = snapshotEntry{name: file.Name, size: int64(file.Size)}
+	}
+	return res
+}
+
+type change struct {
+	kind   string // added, removed, grown, shrunk
+	name   string
+	before int64
+	after  int64
+}
+
+// describe is one line for the change, with its sizes
+func (c change) describe(conf *Config) string {
+	switch c.kind {
+	case "added":
+		return fmt.Sprintf("+ %s (%s)", c.name, conf.formatSize(c.after))
+	case "removed":
+		return fmt.Sprintf("- %s (%s)", c.name, conf.formatSize(c.before))
+	}
+	return fmt.Sprintf("~ %s (%s -> %s)", c.name, conf.formatSize(c.before), conf.formatSize(c.after))
+}
+
+func diffSnapshots(before, after map[string]snapshotEntry) []change {
+	res := []change{}
+	for id, a := range after {
+		b, ok := before[id]
+		switch {
+		case !ok:
+			res = append(res, change{kind: "added", name: a.name, after: a.size})
+		case a.size > b.size:
+			res = append(res, change{kind: "grown", name: a.name, before: b.size, after: a.size})
+		case a.size < b.size:
+			res = append(res, change{kind: "shrunk", name: a.name, before: b.size, after: a.size})
+		}
+	}
+	for id, b := range before {
+		if _, ok := after[id]; !ok {
+			res = append(res, change{kind: "removed", name: b.name, before: b.size})
+		}
+	}
+
+	// biggest changes first
+	delta := func(c change) int64 {
+		if c.after > c.before {
+			return c.after - c.before
+		}
+		return c.before - c.after
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if delta(res[i]) != delta(res[j]) {
+			return delta(res[i]) > delta(res[j])
+		}
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+// summarizeChanges counts changes by kind and lists the largest ones
+func summarizeChanges(conf *Config, changes []change, maxLines int) string {
+	if len(changes) == 0 {
+		return "nothing changed"
+	}
+
+	counts := map[string]int{}
+	for i := range changes {
+		counts[changes[i].kind] += 1
+	}
+
+	lines := []string{fmt.Sprintf("%d added, %d removed, %d grown, %d shrunk",
+		counts["added"], counts["removed"], counts["grown"], counts["shrunk"]), ""}
+	for i := range changes {
+		if i == maxLines {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(changes)-maxLines))
+			break
+		}
+		lines = append(lines, changes[i].describe(conf))
+	}
+	return strings.Join(lines, "\n")
+}
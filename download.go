@@ -0,0 +1,27 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// downloadFile saves a file from the backend into dir, under its name on the drive.
+// Google Docs have no content to download, they would have to be exported instead.
+func downloadFile(conf *Config, file *File, dir string) error {
+	if err := conf.gdriveOnly("downloading"); err != nil {
+		return err
+	}
+	if file.Type != "" {
+		return errors.New("can't download " + file.Name + ", only regular files can be downloaded, not " + file.Type + "s")
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return errors.New("download folder doesn't exist: " + dir)
+	}
+	if _, err := sh(conf.gdrive("files", "download", "--destination", dir, file.ID)...); err != nil {
+		return errors.New("failed to download " + file.Name + ": " + err.Error())
+	}
+	return nil
+}
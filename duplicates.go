@@ -0,0 +1,84 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// duplicateGroup holds files that are likely the same, because they share name and size
+type duplicateGroup struct {
+	name  string
+	size  int
+	paths []string
+}
+
+// wasted is the space that could be reclaimed by keeping only one copy
+func (d duplicateGroup) wasted() int64 {
+	return int64(d.size) * int64(len(d.paths)-1)
+}
+
+func findDuplicates(conf *Config, root *Folder) []duplicateGroup {
+	type key struct {
+		name string
+		size int
+	}
+	groups := map[key][]string{}
+	root.walkFiles(conf, func(path string, file *File) {
+		if file.Size == 0 {
+			return
+		}
+		k := key{name: file.Name, size: file.Size}
+		groups[k] = append(groups[k], path)
+	})
+
+	res := []duplicateGroup{}
+	for k, paths := range groups {
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+		res = append(res, duplicateGroup{name: k.name, size: k.size, paths: paths})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		a := res[i]
+		b := res[j]
+		if a.wasted() != b.wasted() {
+			return a.wasted() > b.wasted()
+		}
+		return a.name < b.name
+	})
+	return res
+}
+
+func newDuplicatesView(conf *Config, root *Folder) *tview.TextView {
+	groups := findDuplicates(conf, root)
+
+	var total int64
+	var sb strings.Builder
+	for i := range groups {
+		group := groups[i]
+		total += group.wasted()
+		fmt.Fprintf(&sb, "%s%s%s %s × %d (%s reclaimable)\n",
+			conf.tag("orange::b"), tview.Escape(group.name), conf.tag("-::-"), conf.formatSize(int64(group.size)), len(group.paths), conf.formatSize(group.wasted()))
+		for _, path := range group.paths {
+			sb.WriteString("    " + tview.Escape(path) + "\n")
+		}
+	}
+
+	view := tview.NewTextView().SetDynamicColors(true)
+	view.SetBorder(true)
+	if len(groups) == 0 {
+		view.SetTitle(" no duplicates found ")
+	} else {
+		view.SetTitle(fmt.Sprintf(" %d groups of likely duplicates, %s reclaimable ", len(groups), conf.formatSize(total)))
+	}
+	view.SetText(sb.String())
+	return view
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// exportRow is one folder or file in CSV/JSON exports. Folder rows summarize their
+// whole subtree, including how many files and folders are in it.
+type exportRow struct {
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Date    string `json:"date,omitempty"`
+	Files   *int   `json:"files,omitempty"`
+	Folders *int   `json:"folders,omitempty"`
+}
+
+var exportColumns = []string{"type", "path", "size", "date", "files", "folders"}
+
+func exportTo(conf *Config, root *Folder, write func(io.Writer) error) error {
+	if conf.Output == "" {
+		return write(os.Stdout)
+	}
+
+	out, err := os.Create(conf.Output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := write(out); err != nil {
+		return err
+	}
+	log("exported "+conf.Export+" to "+conf.Output, INFO)
+	return nil
+}
+
+func exportDate(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+// exportRows walks the tree depth-first, every folder is followed by its content
+func exportRows(conf *Config, root *Folder) []exportRow {
+	res := []exportRow{}
+	var walk func(f *Folder)
+	walk = func(f *Folder) {
+		files, folders := f.files, f.folders
+		res = append(res, exportRow{
+			Type:    "folder",
+			Path:    f.fullPath(),
+			Size:    f.size,
+			Date:    exportDate(f.Date),
+			Files:   &files,
+			Folders: &folders,
+		})
+
+		list := f.fileList(conf)
+		for i := range list {
+			file := list[i]
+			path := f.filePath(file)
+			if conf.hidesFile(path, file) {
+				continue
+			}
+			res = append(res, exportRow{
+				Type: "file",
+				Path: path,
+				Size: int64(file.Size),
+				Date: exportDate(file.Date),
+			})
+		}
+
+		for i := range f.Folders {
+			if !conf.Ignore.matches(f.Folders[i].fullPath(), true) {
+				walk(f.Folders[i])
+			}
+		}
+	}
+	walk(root)
+	return res
+}
+
+// topRows are the n largest files of the tree
+func topRows(conf *Config, root *Folder, n int) []exportRow {
+	files := []pathFile{}
+	root.walkFiles(conf, func(path string, file *File) {
+		files = append(files, pathFile{path: path, file: file})
+	})
+	sort.Slice(files, func(i, j int) bool {
+		a := files[i]
+		b := files[j]
+		return less("size", a.path, b.path, int64(a.file.Size), int64(b.file.Size), a.file.Date, b.file.Date)
+	})
+	if len(files) > n {
+		files = files[:n]
+	}
+
+	res := make([]exportRow, len(files))
+	for i, pf := range files {
+		res[i] = exportRow{
+			Type: "file",
+			Path: pf.path,
+			Size: int64(pf.file.Size),
+			Date: exportDate(pf.file.Date),
+		}
+	}
+	return res
+}
+
+// rows to export, either the whole tree or only the largest files with -top
+func (c *Config) exportRows(root *Folder) []exportRow {
+	if c.Top > 0 {
+		return topRows(c, root, c.Top)
+	}
+	return exportRows(c, root)
+}
+
+func writeCSV(conf *Config, root *Folder, w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(exportColumns); err != nil {
+		return err
+	}
+
+	count := func(n *int) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.Itoa(*n)
+	}
+	for _, row := range conf.exportRows(root) {
+		err := out.Write([]string{row.Type, row.Path, strconv.FormatInt(row.Size, 10), row.Date, count(row.Files), count(row.Folders)})
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func writeJSON(conf *Config, root *Folder, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(conf.exportRows(root))
+}
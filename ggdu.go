@@ -4,21 +4,32 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 	"github.com/rivo/tview"
+	"golang.org/x/term"
 )
 
 var logFile = ""
@@ -28,6 +39,7 @@ type LOG_LEVEL byte
 const (
 	DEBUG LOG_LEVEL = iota
 	INFO
+	WARN
 	ERROR
 )
 
@@ -55,94 +67,354 @@ type Folder struct {
 	Files      []*File
 	Date       int64
 	LastUpdate int64
+	LastScanBy string         `json:",omitempty"` // who fetched it at LastUpdate, for caches shared in a team
+	Skipped    map[string]int `json:",omitempty"` // entries by type that aren't counted, e.g. documents
+	Streamed   bool           `json:",omitempty"` // Files are in the stream store instead, see -stream-to
+	Version    int            `json:",omitempty"` // only on the root: the cacheVersion it was saved with
 
 	// aggregate info, computed on the fly
-	size      int64
-	known     int // aggregate known folders at this level
-	unknown   int // aggregate unknown folders at this level
-	folderIdx map[string]*Folder
-	fileIdx   map[string]*File
-	path      string  // full path
-	parent    *Folder // two-way navigation
-	save      func() error
-	lastIdx   int
+	size       int64
+	directSize int64          // of the files directly in this folder, without subfolders
+	estimated  int64          // part of size that is only estimated, for -estimate-doc-size
+	skipped    map[string]int // aggregate Skipped of the whole subtree
+	files      int            // aggregate files in the whole subtree
+	folders    int            // aggregate folders in the whole subtree
+	known      int            // aggregate known folders at this level
+	unknown    int            // aggregate unknown folders at this level
+	unscanned  int            // aggregate folders in the whole subtree that were never fetched
+	staleTotal int            // aggregate unknown folders in the whole subtree
+	folderIdx  map[string]*Folder
+	path       string  // full path
+	parent     *Folder // two-way navigation
+	save       func() error
+	lastIdx    int
+	dirty      bool         // only on the root: something changed since the cache was last saved
+	virtual    bool         // only on the root of several -root-id: its folders are those roots
+	showOther  bool         // the row of small entries was expanded
+	own        *ownFiles    // what the streamed files add to the aggregates, nil until it's known
+	stream     *streamStore // only on the root: where streamed files are, nil without any
 }
 
 type File struct {
 	ID   string
 	Name string
-	Ext  string
-	Size int // in bytes
+	Ext  string `json:"-"` // derived from Name in rebuild, it would only bloat the cache
+	Size int    // in bytes
 	Date int64
-}
 
-var refreshDelay = 24 * time.Hour
-var tooOld = (time.Now().Add(-refreshDelay)).Unix()
+	// Google Docs don't count against the quota, so their Size is only set to what
+	// the API reports (DocSize) if they are explicitly counted
+	Type    string `json:",omitempty"` // empty for regular files
+	DocSize int    `json:",omitempty"`
+
+	// only set if the backend reports them, gdrive's list doesn't
+	MimeType string `json:",omitempty"`
+	Owner    string `json:",omitempty"`
+
+	estimated bool // Size is a guess of -estimate-doc-size
+}
 
-var log = func(msg string, level LOG_LEVEL) {
-	fmt.Println(msg)
+// about describes the file with whatever extra info the backend reported, empty if none
+func (f *File) about() string {
+	parts := []string{}
+	if f.MimeType != "" {
+		parts = append(parts, f.MimeType)
+	}
+	if f.Owner != "" {
+		parts = append(parts, "owned by "+f.Owner)
+	}
+	return strings.Join(parts, ", ")
 }
 
-const savePath = "db.json"
+var log = stderrLog(INFO)
 
 func main() {
+	conf, err := parseConfig(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+	log = stderrLog(conf.LogLevel)
+
+	if conf.ProgressJSON != "" {
+		w, err := openProgress(conf.ProgressJSON)
+		if err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		defer w.Close()
+	}
+
+	if conf.DescribeCache {
+		if err := describeCache(os.Stdout); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if conf.Reset {
+		if err := resetCache(conf, os.Stdin, os.Stderr); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// compares two caches, e.g. of different accounts, so the regular cache is never loaded
+	if len(conf.Compare) == 2 {
+		if err := writeCompare(conf, conf.Compare[0], conf.Compare[1], os.Stdout); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// parse a saved gdrive listing as if it was the root, without gdrive or the cache
+	if conf.FromFile != "" {
+		raw, err := os.ReadFile(conf.FromFile)
+		if err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		replay := &Folder{ID: conf.RootID, path: "/"}
+		if err := replay.parseList(conf, string(raw)); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		replay.rebuild(conf)
+		if err := writeSummary(conf, replay, os.Stdout); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var data *Folder
-	var err error
-	if fileExists(savePath) {
-		data, err = load(savePath)
+	if fileExists(conf.SavePath) {
+		data, err = load(conf)
 		if err != nil {
-			panic(err)
+			log(err.Error(), ERROR)
+			os.Exit(2)
+		}
+		// the root's ID is stored with the cache, so we never mix trees of different roots
+		if data.ID != conf.RootID {
+			log(fmt.Sprintf("cache %s was built for root %q but %q was requested, use -cache to keep them apart",
+				conf.SavePath, data.ID, conf.RootID), ERROR)
+			os.Exit(2)
 		}
 	} else {
-		data = &Folder{}
+		data = &Folder{ID: conf.RootID, stream: &streamStore{path: streamPath(conf.SavePath)}}
 	}
 	data.path = "/"
+	data.virtual = len(conf.RootIDs) > 1
+
+	if conf.Serve != "" || conf.Export != "" || conf.Tree || conf.Summary || conf.Verify || len(conf.Budgets) > 0 {
+		if data.folderIdx == nil {
+			data.rebuild(conf)
+		}
+	}
+
+	if conf.Verify {
+		mismatches, err := verify(conf, data, conf.VerifySample, os.Stdout)
+		if err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		if len(mismatches) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(conf.Budgets) > 0 {
+		exceeded, err := checkBudgets(conf, data, conf.Budgets, os.Stdout)
+		if err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(2)
+		}
+		if exceeded > 0 {
+			log(fmt.Sprintf("%d of %d budgets exceeded", exceeded, len(conf.Budgets)), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if conf.Tree {
+		if err := writeTree(conf, data, os.Stdout); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if conf.Summary {
+		if err := writeSummary(conf, data, os.Stdout); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if conf.Export != "" {
+		var write func(io.Writer) error
+		switch conf.Export {
+		case "svg":
+			write = func(w io.Writer) error { return writeSVG(conf, data, w) }
+		case "csv":
+			write = func(w io.Writer) error { return writeCSV(conf, data, w) }
+		case "json":
+			write = func(w io.Writer) error { return writeJSON(conf, data, w) }
+		default:
+			log("unsupported export format: "+conf.Export, ERROR)
+			os.Exit(2)
+		}
+
+		if err := exportTo(conf, data, write); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if conf.Serve != "" {
+		if err := serve(conf, data, conf.Serve); err != nil {
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if printed, err := summaryWithoutTerminal(conf, data, os.Stdout); err != nil {
+		log(err.Error(), ERROR)
+		os.Exit(1)
+	} else if printed {
+		return
+	}
+
+	startApp(conf, data)
+}
+
+// summaryWithoutTerminal prints a summary of the cache if out is no terminal, e.g. in CI or
+// when piped there's no one to use the TUI and the summary is the closest thing. It reports if it did.
+func summaryWithoutTerminal(conf *Config, root *Folder, out io.Writer) (bool, error) {
+	if isTerminal(out) {
+		return false, nil
+	}
+	log("stdout is not a terminal, printing a summary of the cache instead of starting the TUI (see -tree and -export for more)", WARN)
+	if root.folderIdx == nil {
+		root.rebuild(conf)
+	}
+	return true, writeSummary(conf, root, out)
+}
 
-	startApp(data)
+// checkRoot makes sure a root that was never scanned exists, so a wrong ID fails loudly.
+// A -remote is only found out about when it's listed, rclone has nothing like gdrive's info.
+func checkRoot(conf *Config, root *Folder) error {
+	if root.ID == "" || root.virtual || root.LastUpdate != 0 || conf.Remote != "" {
+		return nil
+	}
+	_, err := Info(conf, root.ID)
+	return err
+}
+
+// isTerminal reports if w is a terminal the TUI can run in
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
 }
 
-func startApp(root *Folder) {
+func startApp(conf *Config, root *Folder) {
+	if conf.NoColor {
+		monochrome()
+	}
 	app := tview.NewApplication()
+	defer restoreOnPanic(app)
+
+	state, err := loadState(conf)
+	if err != nil {
+		log(err.Error(), ERROR)
+		os.Exit(1)
+	}
+	if err := state.useBarStyle(conf); err != nil {
+		log("failed to save state: "+err.Error(), ERROR)
+	}
 
 	debug := tview.NewTextView().SetTextAlign(tview.AlignLeft)
-	debugTxt := []string{}
+	status := &statusLine{max: 3, view: debug, queue: func(fn func()) { app.QueueUpdateDraw(fn) }}
+	logs := &logBuffer{max: 1000}
+	// scans and deletes log from the background, messages to the file must not mix
+	var logFileMu sync.Mutex
 	debugMsg := func(msg string, level LOG_LEVEL) {
+		if level >= conf.LogLevel {
+			logs.add(msg, level)
+		}
+
 		if logFile != "" {
+			logFileMu.Lock()
 			f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 			if err == nil {
 				f.WriteString(msg)
 				f.Write([]byte{'\n'})
 				f.Close()
 			}
+			logFileMu.Unlock()
 		}
 
 		if level < INFO {
 			return
 		}
-		debugTxt = append(debugTxt, msg)
-		if len(debugTxt) > 3 {
-			debugTxt = debugTxt[len(debugTxt)-3:]
-		}
-		debug.SetText(strings.Join(debugTxt, "\n"))
+		status.add(msg)
 	}
 	log = debugMsg
-	debugMsg("Keys: l = load the folder, x = recursively load everything in a folder, F5 = refresh", INFO)
-	debugMsg("Temporary cache is stored in: "+savePath, INFO)
-	debugMsg("By default fetch data only every "+refreshDelay.String()+" (override with f+l or f+x)", INFO)
+	debugMsg("Keys: l = load the folder, x = recursively load everything in a folder, r/F5 = refresh this folder, space = mark, d = delete marked, X = deleted before, . = show only this folder, ~ = back to root, s = sort, / = jump to name, : = go to path, G = group folders, a = direct/aggregate sizes, m = bars of largest, o = collapse small items, F = files/folders only, R = of root, % = of quota, V = tree, T = treemap, D = duplicates, H = histogram, O = owners, n = newest files, c = copy listing, g = download file, p = preview file, B = bookmark, ' = bookmarks, L = logs", INFO)
+	debugMsg("Temporary cache is stored in: "+conf.SavePath+" (sizes with ~ are missing unscanned subfolders, ▸ marks folders not scanned yet)", INFO)
+	debugMsg("By default fetch data only every "+conf.MaxAge.String()+" (override with f+l or f+x)", INFO)
 
 	root.save = func() error {
-		return save(root)
+		return save(conf, root)
+	}
+	if err := checkRoot(conf, root); err != nil {
+		log = stderrLog(conf.LogLevel)
+		log(err.Error(), ERROR)
+		os.Exit(1)
+	}
+
+	// without network we can still show what's cached, only without anything there's nothing to do
+	warning := ""
+	if err := root.ensureData(conf, false, nil); err != nil {
+		if root.LastUpdate == 0 {
+			log = stderrLog(conf.LogLevel)
+			log(err.Error(), ERROR)
+			os.Exit(1)
+		}
+		log(err.Error(), ERROR)
+		warning = " refresh failed, showing cached data"
 	}
-	root.ensureData(false, nil)
 	// we picked one field that must definitely not be nil after a successful refresh, which might have happened
 	if root.folderIdx == nil {
-		root.rebuild()
+		root.rebuild(conf)
+	}
+
+	// from here on scans run in the background, they hand what they change over to the UI's goroutine
+	updateTree = func(fn func()) {
+		done := make(chan struct{})
+		app.QueueUpdate(func() {
+			fn()
+			close(done)
+		})
+		<-done
 	}
 
 	curFolder := root
-	var listItems []*Folder
+	var listItems []entry
 
 	list := tview.NewList().ShowSecondaryText(false)
+	if conf.NoColor {
+		list.SetSelectedStyle(tcell.StyleDefault.Reverse(true))
+	}
 	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Rune() {
 		case 'k':
@@ -156,123 +428,866 @@ func startApp(root *Folder) {
 	header := tview.NewTextView().
 		SetTextAlign(tview.AlignLeft)
 
+	details := tview.NewTextView().
+		SetTextAlign(tview.AlignLeft).
+		SetTextColor(conf.color(tcell.ColorGray))
+
 	grid := tview.NewGrid().
-		SetRows(1, 0, 3).
+		SetRows(1, 0, 1, 3).
 		SetColumns(0).
 		AddItem(header, 0, 0, 1, 1, 0, 0, false).
 		AddItem(list, 1, 0, 1, 1, 0, 0, true).
-		AddItem(debug, 2, 0, 1, 1, 0, 0, false)
+		AddItem(details, 2, 0, 1, 1, 0, 0, false).
+		AddItem(debug, 3, 0, 1, 1, 0, 0, false)
+
+	// details are about the selected folder, or the current one if a file is selected
+	updateDetails := func(idx int) {
+		f := curFolder
+		if idx >= 0 && idx < len(listItems) && listItems[idx].folder != nil {
+			f = listItems[idx].folder
+		}
+		position := fmt.Sprintf("item %d of %d", idx+1, list.GetItemCount())
+		text := position + " | " + f.Name + "/: " + f.fileStats(conf).describe(conf)
+		if f.LastUpdate != 0 {
+			text += " | scanned " + formatDate(f.LastUpdate)
+			if f.LastScanBy != "" {
+				text += " by " + f.LastScanBy
+			}
+		}
+		if idx >= 0 && idx < len(listItems) && listItems[idx].file != nil {
+			if about := listItems[idx].file.about(); about != "" {
+				text += " | " + about
+			}
+		}
+		details.SetText(text)
+	}
+	list.SetChangedFunc(func(idx int, _ string, _ string, _ rune) {
+		updateDetails(idx)
+	})
 
 	// box := tview.NewGrid().SetBorder(true).SetTitle("Explore " + f.path)
 	// box.Set
 
+	pages := tview.NewPages().
+		AddPage("explorer", grid, true, true)
+
+	// overlays are views on top of the explorer, closed with Esc, q, or the key that opened them
+	var overlayKey rune
+	showOverlay := func(key rune, p tview.Primitive) {
+		overlayKey = key
+		pages.AddAndSwitchToPage("overlay", p, true)
+		app.SetFocus(p)
+	}
+	closeOverlay := func() {
+		pages.SwitchToPage("explorer")
+		pages.RemovePage("overlay")
+		app.SetFocus(list)
+	}
+
+	dirsFirst := conf.DirsFirst
+	ofQuota := false
+	width := 0
+	marked := marks{}
+	top := root
+	direct := false
+	ofLargest := false
+	ofRoot := false
+	otherBelow := conf.OtherThreshold / 100
+	only := ""
+
 	var selectFn func(*Folder)
 	selectFn = func(f *Folder) {
 		folderChanged := f != curFolder
 		curFolder = f
-		listItems = f.explorer(list, folderChanged, selectFn)
-		title := tview.Escape(filepath.Join(f.path, f.Name))
-		header.SetText("--- " + title + " (" + formatSize(f.size) + ") ---")
+		// e.g. a bookmark can lead out of the folder we re-rooted at
+		if !f.within(top) {
+			top = root
+		}
+		v := view{
+			sortBy:     state.sortFor(conf, f.fullPath()),
+			dirsFirst:  dirsFirst,
+			ofQuota:    ofQuota,
+			width:      width,
+			marked:     marked,
+			top:        top,
+			direct:     direct,
+			ofLargest:  ofLargest,
+			otherBelow: otherBelow,
+			ofRoot:     ofRoot,
+			root:       root,
+			only:       only,
+		}
+		listItems = f.explorer(conf, list, v, folderChanged, selectFn)
+		updateDetails(list.GetCurrentItem())
+		title := tview.Escape(f.fullPath())
+		if root.ID != "" {
+			title = tview.Escape(root.ID) + ":" + title
+		}
+		info := f.sizeLabel(conf)
+		if direct {
+			info += ", folders show direct sizes only"
+		}
+		if ofLargest && !ofQuota && !ofRoot {
+			info += ", bars relative to the largest entry"
+		}
+		if ofRoot && !ofQuota && root.size > 0 {
+			info += fmt.Sprintf(", %.1f%% of everything", float64(f.size)/float64(root.size)*100)
+		}
+		if ofQuota {
+			info += fmt.Sprintf(", %.1f%% of %s quota", float64(f.size)/float64(conf.Quota)*100, conf.formatSize(conf.Quota))
+		}
+		if only != "" {
+			info += ", " + only + " only"
+		}
+		if f.unscanned > 0 && f.folders > 0 {
+			scanned := max(f.folders-f.unscanned, 0)
+			info += fmt.Sprintf(", %.0f%% of folders scanned", float64(scanned)/float64(f.folders)*100)
+		}
+		if conf.OwnedOnly {
+			info += ", owned by me only"
+		}
+		if conf.NewerThan > 0 {
+			info += ", files newer than " + conf.NewerThan.String()
+		}
+		if conf.OlderThan > 0 {
+			info += ", files older than " + conf.OlderThan.String()
+		}
+		if conf.CountDocs {
+			info += ", incl. Google Docs"
+		}
+		if conf.EstimateDocSize {
+			info += ", sizes of Google Docs (~) are estimates"
+		}
+		header.SetText("--- " + title + " (" + info + ") ---" + f.skippedNote() + warning)
 		// debugMsg("rendered " + f.path)
 	}
 
 	var forceMode = false
 
+	// fetch only the direct children of the folder we are looking at, then show what changed
+	refreshCurrent := func() {
+		folder := curFolder
+		before := folder.snapshot(conf)
+		path := folder.fullPath()
+		go func() {
+			defer restoreOnPanic(app)
+			log("refresh "+path, INFO)
+			if err := folder.ensureData(conf, true, nil); err != nil {
+				log(err.Error(), ERROR)
+				app.QueueUpdateDraw(func() { selectFn(curFolder) })
+				return
+			}
+
+			app.QueueUpdateDraw(func() {
+				changes := diffSnapshots(before, folder.snapshot(conf))
+				selectFn(curFolder)
+				modal := tview.NewModal().
+					SetText("Changes in " + folder.fullPath() + "\n\n" + summarizeChanges(conf, changes, 10)).
+					AddButtons([]string{"OK"}).
+					SetDoneFunc(func(int, string) { closeOverlay() })
+				showOverlay('r', modal)
+			})
+		}()
+	}
+
+	// delete everything marked, after one confirmation for all of them
+	deleteMarkedEntries := func() {
+		if len(marked) == 0 {
+			log("nothing marked, press space to mark entries for deletion", INFO)
+			return
+		}
+		if err := conf.gdriveOnly("deleting"); err != nil {
+			log(err.Error(), WARN)
+			return
+		}
+		run := func() {
+			// the goroutine only gets a copy, marks and the tree are only changed here on the UI's side
+			todo := maps.Clone(marked)
+			go func() {
+				defer restoreOnPanic(app)
+				deleted, err := deleteMarked(conf, todo)
+				if err != nil {
+					log(err.Error(), ERROR)
+				}
+				app.QueueUpdateDraw(func() {
+					if err := removeDeleted(conf, root, marked, deleted); err != nil {
+						log(err.Error(), ERROR)
+					}
+					// the current folder may have been deleted itself
+					selectFn(curFolder.attached())
+				})
+			}()
+		}
+		if conf.NoConfirm {
+			run()
+			return
+		}
+
+		text := fmt.Sprintf("Permanently delete %d marked entries (%s)?\n\nFolders are deleted with everything in them.",
+			len(marked.outermost()), conf.formatSize(marked.size()))
+		modal := tview.NewModal().
+			SetText(text).
+			AddButtons([]string{"Delete", "Cancel"}).
+			SetDoneFunc(func(_ int, label string) {
+				closeOverlay()
+				if label == "Delete" {
+					run()
+				}
+			})
+		showOverlay('d', modal)
+	}
+
+	// quitting with changes that aren't in the cache yet asks first, they would be lost otherwise
+	quit := func() {
+		if !root.dirty {
+			app.Stop()
+			return
+		}
+		done := func(label string) {
+			switch label {
+			case "Yes":
+				if err := root.save(); err != nil {
+					closeOverlay()
+					log("failed to save cache: "+err.Error(), ERROR)
+					return
+				}
+				app.Stop()
+			case "No":
+				app.Stop()
+			default:
+				closeOverlay()
+			}
+		}
+		modal := tview.NewModal().
+			SetText("Save changes before quitting? (y/n, q to cancel)").
+			AddButtons([]string{"Yes", "No", "Cancel"}).
+			SetDoneFunc(func(_ int, label string) { done(label) })
+		modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Rune() {
+			case 'y':
+				done("Yes")
+				return nil
+			case 'n':
+				done("No")
+				return nil
+			}
+			return event
+		})
+		showOverlay('q', modal)
+	}
+
+	// downloads run in the background, the result is shown in a modal once they are done
+	downloadSelected := func(file *File) {
+		log("downloading "+file.Name+" ("+conf.formatSize(int64(file.Size))+") to "+conf.DownloadDir, INFO)
+		go func() {
+			defer restoreOnPanic(app)
+			text := "Downloaded " + file.Name + " to " + conf.DownloadDir
+			err := downloadFile(conf, file, conf.DownloadDir)
+			if err != nil {
+				log(err.Error(), ERROR)
+				text = err.Error()
+			} else {
+				log(text, INFO)
+			}
+			app.QueueUpdateDraw(func() {
+				modal := tview.NewModal().
+					SetText(text).
+					AddButtons([]string{"OK"}).
+					SetDoneFunc(func(_ int, _ string) { closeOverlay() })
+				showOverlay('g', modal)
+			})
+		}()
+	}
+
+	// type-ahead: after / every typed rune extends the prefix we jump to, until a pause or Esc
+	var typeahead []rune
+	var typeaheadAt time.Time
+	jumpTo := func(prefix string) {
+		prefix = strings.ToLower(prefix)
+		for i := range listItems {
+			e := listItems[i]
+			if (e.folder != nil || e.file != nil) && strings.HasPrefix(strings.ToLower(e.name()), prefix) {
+				list.SetCurrentItem(i)
+				return
+			}
+		}
+	}
+
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if typeahead != nil && time.Since(typeaheadAt) > typeaheadTimeout {
+			typeahead = nil
+		}
+		if typeahead != nil {
+			switch event.Key() {
+			case tcell.KeyEscape, tcell.KeyEnter:
+				typeahead = nil
+				return nil
+			case tcell.KeyBackspace, tcell.KeyBackspace2:
+				if len(typeahead) > 0 {
+					typeahead = typeahead[:len(typeahead)-1]
+				}
+			case tcell.KeyRune:
+				typeahead = append(typeahead, event.Rune())
+			default:
+				typeahead = nil
+				return event
+			}
+			typeaheadAt = time.Now()
+			jumpTo(string(typeahead))
+			log("jump to: "+string(typeahead), INFO)
+			return nil
+		}
+
+		if name, _ := pages.GetFrontPage(); name == "overlay" {
+			// typing into a prompt, where q is just a letter
+			if _, ok := app.GetFocus().(*tview.InputField); ok && event.Key() != tcell.KeyEscape {
+				return event
+			}
+			if event.Key() == tcell.KeyEscape || event.Rune() == overlayKey || event.Rune() == 'q' {
+				closeOverlay()
+				return nil
+			}
+			return event
+		}
+
 		switch event.Key() {
 		case tcell.KeyEscape:
-			app.Stop()
+			quit()
 			return nil // stop propagation
 
-		// case tcell.KeyF5:
-		// 	go func() {
-		// 		curFolder.ensureData(true, nil)
-		// 		selectFn(curFolder)
-		// 		app.Draw()
-		// 	}()
+		case tcell.KeyF5:
+			refreshCurrent()
+			return nil
 
 		case tcell.KeyRune:
 			ch := event.Rune()
 			if ch == 'q' {
-				app.Stop()
+				quit()
 				return nil
 			}
 
-			if ch == 'l' || ch == 'x' {
-				i := list.GetCurrentItem()
-				if i >= len(listItems) {
-					return nil
-				}
-				folder := listItems[i]
-				if folder == nil {
-					return nil
-				}
-
-				var deep *goDeep
-				if ch == 'x' {
-					deep = &goDeep{max: 1, cur: 0, onUpdate: func(cur *Folder) {
-						for ; cur != nil; cur = cur.parent {
-							if cur == curFolder {
-								selectFn(cur)
-								break
-							}
-						}
-						app.Draw()
-					}}
-				}
+			if ch == '/' {
+				typeahead = []rune{}
+				typeaheadAt = time.Now()
+				log("jump to: (type a name)", INFO)
+				return nil
+			}
 
-				go func() {
-					msg := "load " + folder.path
-					if forceMode {
-						msg += " (force refresh)"
+			if ch == ':' {
+				input := tview.NewInputField().SetText(curFolder.fullPath())
+				input.SetBorder(true).SetTitle(" go to path (enter to go, esc to cancel) ")
+				input.SetDoneFunc(func(key tcell.Key) {
+					closeOverlay()
+					if key != tcell.KeyEnter {
+						return
 					}
-					log(msg, INFO)
+					path := input.GetText()
+					f, missing := resolvePath(root, path)
+					if missing != "" {
+						log("there is no "+missing+" in "+f.fullPath()+", went as far as possible", WARN)
+					}
+					selectFn(f)
+				})
+				prompt := tview.NewFlex().SetDirection(tview.FlexRow).
+					AddItem(input, 3, 0, true).
+					AddItem(nil, 0, 1, false)
+				showOverlay(ch, prompt)
+				app.SetFocus(input)
+				return nil
+			}
 
-					folder.ensureData(forceMode, deep)
+			if ch == 'T' {
+				showOverlay(ch, newTreemapView(conf, func() *Folder { return curFolder }))
+				return nil
+			}
 
-					if deep != nil {
-						log("all done for "+folder.path, INFO)
-					}
+			if ch == 'V' {
+				showOverlay(ch, newFolderTreeView(conf, root))
+				return nil
+			}
 
-					selectFn(curFolder)
-					app.Draw()
-				}()
+			if ch == 'D' {
+				showOverlay(ch, newDuplicatesView(conf, root))
+				return nil
+			}
 
+			if ch == 'B' {
+				path := curFolder.fullPath()
+				if state.addBookmark(path) {
+					if err := state.save(); err != nil {
+						log("failed to save state: "+err.Error(), ERROR)
+					}
+					log("bookmarked "+path, INFO)
+				}
 				return nil
 			}
 
-			if ch == 'f' {
-				forceMode = true
-			} else {
-				forceMode = false
+			if ch == '\'' {
+				showOverlay(ch, newBookmarksView(conf, root, state, func(f *Folder) {
+					closeOverlay()
+					selectFn(f)
+				}))
+				return nil
 			}
 
-		}
-		return event // Continue processing other events
-	})
+			if ch == 'n' {
+				showOverlay(ch, newRecentView(conf, root))
+				return nil
+			}
 
-	selectFn(curFolder)
-	app.SetRoot(grid, true).SetFocus(list)
+			if ch == 'O' {
+				showOverlay(ch, newOwnersView(conf, root))
+				return nil
+			}
 
-	if err := app.Run(); err != nil {
-		fmt.Println(err)
-	}
-}
+			if ch == 'X' {
+				showOverlay(ch, newDeletionsView(conf))
+				return nil
+			}
 
-const delim = "^^^^^"
+			if ch == 'H' {
+				showOverlay(ch, newHistogramView(conf, root))
+				return nil
+			}
 
-var gdriveListHeader = strings.Join([]string{"Id", "Name", "Type", "Size", "Created"}, delim)
+			if ch == 'L' {
+				view := tview.NewTextView().SetText(logs.String())
+				view.SetBorder(true).SetTitle(" logs (" + conf.LogLevel.String() + " and above) ")
+				view.ScrollToEnd()
+				showOverlay(ch, view)
+				return nil
+			}
 
-func save(root *Folder) error {
-	res, err := json.Marshal(root)
-	if err != nil {
-		return err
-	}
+			if ch == 'r' {
+				refreshCurrent()
+				return nil
+			}
 
-	return os.WriteFile(savePath, res, 0644)
-}
+			if ch == 'g' {
+				if err := conf.gdriveOnly("downloading"); err != nil {
+					log(err.Error(), WARN)
+					return nil
+				}
+				i := list.GetCurrentItem()
+				if i >= len(listItems) || listItems[i].file == nil {
+					log("only files can be downloaded, select one first", INFO)
+					return nil
+				}
+				downloadSelected(listItems[i].file)
+				return nil
+			}
 
-func fileExists(path string) bool {
+			if ch == 'p' {
+				if err := conf.gdriveOnly("previewing"); err != nil {
+					log(err.Error(), WARN)
+					return nil
+				}
+				i := list.GetCurrentItem()
+				if i >= len(listItems) || listItems[i].file == nil || previewKind(listItems[i].file) == "" {
+					log("select a text file or image to preview it", INFO)
+					return nil
+				}
+				file := listItems[i].file
+				log("loading a preview of "+file.Name, INFO)
+				go func() {
+					defer restoreOnPanic(app)
+					text, err := preview(conf, file)
+					if err != nil {
+						log(err.Error(), ERROR)
+						return
+					}
+					app.QueueUpdateDraw(func() { showOverlay('p', newPreviewView(conf, file, text)) })
+				}()
+				return nil
+			}
+
+			if ch == 'c' {
+				text := curFolder.fullPath() + " (" + conf.formatSize(curFolder.size) + ")\n" + plainListing(list)
+				if err := copyToClipboard(text); err != nil {
+					log("failed to copy the listing: "+err.Error(), ERROR)
+					return nil
+				}
+				log(fmt.Sprintf("copied the listing of %s (%d items) to the clipboard", curFolder.fullPath(), list.GetItemCount()), INFO)
+				return nil
+			}
+
+			if ch == ' ' {
+				i := list.GetCurrentItem()
+				if i < len(listItems) {
+					marked.toggle(curFolder, listItems[i])
+					selectFn(curFolder)
+					list.SetCurrentItem(min(i+1, list.GetItemCount()-1))
+				}
+				return nil
+			}
+
+			if ch == 'd' {
+				deleteMarkedEntries()
+				return nil
+			}
+
+			if ch == '.' {
+				i := list.GetCurrentItem()
+				if i < len(listItems) && listItems[i].folder != nil {
+					curFolder.lastIdx = i
+					top = listItems[i].folder
+				} else {
+					top = curFolder
+				}
+				log("showing only "+top.fullPath()+", ~ goes back to the real root", INFO)
+				selectFn(top)
+				return nil
+			}
+
+			if ch == '~' {
+				top = root
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 's' {
+				path := curFolder.fullPath()
+				cur := state.sortFor(conf, path)
+				next := sortOrders[0]
+				for i := range sortOrders {
+					if sortOrders[i] == cur {
+						next = sortOrders[(i+1)%len(sortOrders)]
+					}
+				}
+				state.setSort(conf, path, next)
+				if err := state.save(); err != nil {
+					log("failed to save state: "+err.Error(), ERROR)
+				}
+				log("sort "+path+" by "+next, INFO)
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == '%' {
+				if conf.Quota <= 0 {
+					log("drive quota is unknown, pass it with -quota (e.g. -quota 100gb)", INFO)
+					return nil
+				}
+				ofQuota = !ofQuota
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 'a' {
+				direct = !direct
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 'm' {
+				ofLargest = !ofLargest
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 'o' {
+				if otherBelow > 0 {
+					otherBelow = 0
+				} else {
+					otherBelow = max(conf.OtherThreshold, 1) / 100
+				}
+				selectFn(curFolder)
+				return nil
+			}
+
+			// cycles through files only, folders only and everything
+			if ch == 'F' {
+				switch only {
+				case "":
+					only = "files"
+				case "files":
+					only = "folders"
+				default:
+					only = ""
+				}
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 'R' {
+				ofRoot = !ofRoot
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 'G' {
+				dirsFirst = !dirsFirst
+				selectFn(curFolder)
+				return nil
+			}
+
+			if ch == 'l' || ch == 'x' {
+				i := list.GetCurrentItem()
+				if i >= len(listItems) {
+					return nil
+				}
+				folder := listItems[i].folder
+				if folder == nil {
+					return nil
+				}
+
+				var deep *goDeep
+				if ch == 'x' {
+					deep = &goDeep{max: 1, cur: 0, onUpdate: func(cur *Folder) {
+						app.QueueUpdateDraw(func() {
+							for ; cur != nil; cur = cur.parent {
+								if cur == curFolder {
+									selectFn(cur)
+									break
+								}
+							}
+						})
+					}}
+				}
+
+				force := forceMode
+				go func() {
+					defer restoreOnPanic(app)
+					msg := "load " + folder.path
+					if force {
+						msg += " (force refresh)"
+					}
+					log(msg, INFO)
+
+					if deep != nil && conf.ScanTimeout > 0 {
+						var cancel context.CancelFunc
+						deep.ctx, cancel = context.WithTimeout(context.Background(), conf.ScanTimeout)
+						defer cancel()
+					}
+
+					err := folder.ensureData(conf, force, deep)
+					if errors.Is(err, errScanTimeout) {
+						log(err.Error(), WARN)
+					} else if err != nil {
+						log(err.Error(), ERROR)
+					} else if deep != nil {
+						log("all done for "+folder.path, INFO)
+					}
+
+					app.QueueUpdateDraw(func() {
+						if errors.Is(err, errScanTimeout) {
+							warning = " scan stopped after " + conf.ScanTimeout.String() + ", the rest is still unscanned"
+						}
+						selectFn(curFolder)
+					})
+				}()
+
+				return nil
+			}
+
+			if ch == 'f' {
+				forceMode = true
+			} else {
+				forceMode = false
+			}
+
+		}
+		return event // Continue processing other events
+	})
+
+	// re-render whenever the terminal changes size, so columns fit the new width
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		if w, _ := screen.Size(); w != width {
+			width = w
+			selectFn(curFolder)
+		}
+		return false
+	})
+
+	// keep what was scanned before fresh, one folder per tick. Only the fetch runs in the
+	// background, the tree is picked from and updated on the UI's goroutine.
+	if conf.AutoRefresh > 0 {
+		go func() {
+			defer restoreOnPanic(app)
+			for range time.Tick(conf.AutoRefresh) {
+				pick := make(chan *Folder, 1)
+				app.QueueUpdate(func() {
+					conf.updateTooOld(time.Now())
+					pick <- nextStale(conf, root)
+				})
+				folder := <-pick
+				if folder == nil {
+					continue
+				}
+
+				log("auto-refresh "+folder.fullPath(), DEBUG)
+				raw, err := sh(folder.listCommand(conf)...)
+				app.QueueUpdateDraw(func() {
+					if err == nil {
+						err = folder.applyList(conf, raw)
+					}
+					if err != nil {
+						log(err.Error(), ERROR)
+					}
+					selectFn(curFolder)
+				})
+			}
+		}()
+	}
+
+	if !conf.NoMouse {
+		app.EnableMouse(true)
+
+		// a click on an entry opens it like Enter, tview would only select it before
+		// opening, which messes up where we were in the folder we leave
+		list.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+			if action != tview.MouseLeftClick {
+				return action, event
+			}
+			_, y := event.Position()
+			_, top, _, height := list.GetInnerRect()
+			offset, _ := list.GetOffset()
+			idx := offset + y - top
+			if y < top || y >= top+height || idx >= list.GetItemCount() {
+				return action, event
+			}
+			list.SetCurrentItem(idx)
+			list.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+			return action, nil
+		})
+
+		// a click on a part of the path in the header jumps there
+		header.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+			if action != tview.MouseLeftClick {
+				return action, event
+			}
+			x, _ := event.Position()
+			left, _, _, _ := header.GetInnerRect()
+			col := x - left - len("--- ")
+			if root.ID != "" {
+				col -= runewidth.StringWidth(root.ID + ":")
+			}
+			if f := curFolder.atPathColumn(col); f != nil {
+				selectFn(f)
+			}
+			return action, nil
+		})
+	}
+
+	selectFn(curFolder)
+	app.SetRoot(pages, true).SetFocus(list)
+	if conf.View == "tree" {
+		showOverlay('V', newFolderTreeView(conf, root))
+	}
+
+	if err := app.Run(); err != nil {
+		log = stderrLog(conf.LogLevel)
+		log(err.Error(), ERROR)
+	}
+}
+
+// restoreOnPanic stops the app, which gives the terminal back, before a panic is reported.
+// Otherwise the terminal is left garbled. It has to be deferred in every goroutine of the app.
+func restoreOnPanic(app *tview.Application) {
+	if r := recover(); r != nil {
+		app.Stop()
+		fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", r, debug.Stack())
+		os.Exit(2)
+	}
+}
+
+// monochrome makes tview render everything in the terminal's default colors
+func monochrome() {
+	tview.Styles.PrimitiveBackgroundColor = tcell.ColorDefault
+	tview.Styles.ContrastBackgroundColor = tcell.ColorDefault
+	tview.Styles.MoreContrastBackgroundColor = tcell.ColorDefault
+	tview.Styles.BorderColor = tcell.ColorDefault
+	tview.Styles.TitleColor = tcell.ColorDefault
+	tview.Styles.GraphicsColor = tcell.ColorDefault
+	tview.Styles.PrimaryTextColor = tcell.ColorDefault
+	tview.Styles.SecondaryTextColor = tcell.ColorDefault
+	tview.Styles.TertiaryTextColor = tcell.ColorDefault
+	tview.Styles.InverseTextColor = tcell.ColorDefault
+	tview.Styles.ContrastSecondaryTextColor = tcell.ColorDefault
+}
+
+const typeaheadTimeout = 1500 * time.Millisecond
+
+const delim = "^^^^^"
+
+var gdriveListColumns = []string{"Id", "Name", "Type", "Size", "Created"}
+var gdriveListHeader = strings.Join(gdriveListColumns, delim)
+
+// cacheVersion is the schema version of the cache, it goes up with every change that older
+// versions of ggdu would misread. Caches from before it was introduced have none, they are version 1.
+const cacheVersion = 1
+
+// save writes the tree to the cache, unless nothing changed since it was last saved or loaded
+func save(conf *Config, root *Folder) error {
+	if !root.dirty && fileExists(conf.SavePath) {
+		log("cache is unchanged, not saving", DEBUG)
+		return nil
+	}
+
+	if conf.StableCache {
+		root.sortByID()
+	}
+	root.Version = cacheVersion
+	res, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so a crash mid-write never leaves a truncated cache.
+	// The mode is set explicitly, the umask or a leftover temp file could change it otherwise.
+	tmp := conf.SavePath + ".tmp"
+	if err := os.WriteFile(tmp, res, conf.CacheMode); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, conf.CacheMode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, conf.SavePath); err != nil {
+		return err
+	}
+	root.dirty = false
+	return nil
+}
+
+// resetCache deletes the cache after asking on in, unless -force is set.
+// The UI state (sort orders, bookmarks) is kept.
+func resetCache(conf *Config, in io.Reader, out io.Writer) error {
+	if !fileExists(conf.SavePath) {
+		fmt.Fprintln(out, "no cache at "+conf.SavePath)
+		return nil
+	}
+
+	if !conf.Force {
+		fmt.Fprintf(out, "delete the cache at %s? [y/N] ", conf.SavePath)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Fprintln(out, "kept the cache")
+			return nil
+		}
+	}
+
+	if err := os.Remove(conf.SavePath); err != nil {
+		return errors.New("failed to delete cache: " + err.Error())
+	}
+	os.Remove(conf.SavePath + ".tmp")
+	os.Remove(streamPath(conf.SavePath))
+	fmt.Fprintln(out, "deleted "+conf.SavePath)
+	return nil
+}
+
+// sortByID orders all children in the subtree by ID, instead of however gdrive returned them,
+// so saving the same tree always gives the same bytes
+func (f *Folder) sortByID() {
+	all := []*Folder{f}
+	for i := 0; i < len(all); i++ {
+		cur := all[i]
+		slices.SortFunc(cur.Folders, func(a, b *Folder) int { return strings.Compare(a.ID, b.ID) })
+		slices.SortFunc(cur.Files, func(a, b *File) int { return strings.Compare(a.ID, b.ID) })
+		all = append(all, cur.Folders...)
+	}
+}
+
+func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	if errors.Is(err, os.ErrNotExist) {
 		return false
@@ -284,265 +1299,974 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-func load(path string) (*Folder, error) {
-	raw, err := os.ReadFile(path)
+func load(conf *Config) (*Folder, error) {
+	raw, err := os.ReadFile(conf.SavePath)
 	if err != nil {
 		return nil, err
 	}
 
 	res := Folder{}
 	err = json.Unmarshal(raw, &res)
+	if err == nil && res.Version > cacheVersion {
+		return nil, fmt.Errorf("cache %s has version %d, this ggdu only reads up to version %d, please update it", conf.SavePath, res.Version, cacheVersion)
+	}
+
+	all := []*Folder{&res}
+	for i := 0; i < len(all); i++ {
+		cur := all[i]
+		all = append(all, cur.Folders...)
+		cur.save = func() error {
+			return save(conf, &res)
+		}
+	}
+	res.path = "/"
+	res.stream = &streamStore{path: streamPath(conf.SavePath)}
+	res.rebuild(conf)
+
+	return &res, err
+}
+
+const MAX_COUNT = 500
+
+// listCommand builds the gdrive (or rclone with -remote) call that lists this folder. Every argument is passed
+// as-is to exec, there is no shell involved. The single quotes in queries are not
+// shell quoting but string literals of Drive's query language, so they have to stay.
+func (f *Folder) listCommand(conf *Config) []string {
+	if conf.Remote != "" {
+		return []string{"rclone", "lsjson", f.remotePath(conf)}
+	}
+	cmd := conf.gdrive("files", "list", "--max", strconv.Itoa(MAX_COUNT))
+	if conf.GdriveJSON {
+		cmd = append(cmd, "--json")
+	} else {
+		cmd = append(cmd, "--field-separator", delim)
+	}
+	if conf.OwnedOnly {
+		// only what counts against our own quota, which needs a query instead of --parent
+		query := "'me' in owners and trashed = false"
+		if f.ID != "" {
+			query = queryString(f.ID) + " in parents and " + query
+		}
+		cmd = append(cmd, "--query", query)
+	} else if f.ID != "" {
+		cmd = append(cmd, "--parent", f.ID)
+	}
+	return cmd
+}
+
+// queryString quotes s as a string literal in Drive's query language
+func queryString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+func (f *Folder) getFiles(ctx context.Context, conf *Config) error {
+	if f.virtual {
+		return f.getRoots(conf)
+	}
+	raw, err := shContext(ctx, f.listCommand(conf)...)
+	if err != nil {
+		return err
+	}
+	updateTree(func() { err = f.parseList(conf, raw) })
+	return err
+}
+
+// getRoots "lists" the root of several -root-id, its folders are exactly those roots.
+// Their contents are fetched like for any other folder.
+func (f *Folder) getRoots(conf *Config) error {
+	names := []string{}
+	for _, id := range conf.RootIDs {
+		info, err := Info(conf, id)
+		if err != nil {
+			return err
+		}
+		name := info.Name
+		if name == "" {
+			name = id
+		}
+		names = append(names, name)
+	}
+
+	updateTree(func() {
+		cached := map[string]*Folder{}
+		for i := range f.Folders {
+			cached[f.Folders[i].ID] = f.Folders[i]
+		}
+
+		folders := []*Folder{}
+		for i, id := range conf.RootIDs {
+			if folder, ok := cached[id]; ok {
+				folder.Name = names[i]
+				folders = append(folders, folder)
+				continue
+			}
+			folders = append(folders, &Folder{ID: id, Name: names[i], save: f.save})
+		}
+
+		f.Folders = folders
+		f.Files = nil
+		f.Streamed = false
+		f.own = nil
+		f.Skipped = nil
+		f.markScanned(conf)
+		f.markDirty()
+	})
+	return nil
+}
+
+// listRow is one entry of a gdrive listing, whichever format it came in
+type listRow struct {
+	ID   string
+	Name string
+	Type string // as gdrive calls it: regular, folder, document, shortcut, ...
+	Size string // as gdrive prints it, e.g. 1.2 MB, or in bytes
+	Date int64
+
+	// only in JSON listings
+	MimeType string
+	Owner    string
+}
+
+// parseDelimitedList reads the rows of gdrive files list with --field-separator, the
+// format of older gdrive versions
+func parseDelimitedList(raw string) ([]listRow, error) {
+	// gdrive on Windows terminates lines with CRLF, which would stick to the last column
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	header := lines[0]
+	if header != gdriveListHeader {
+		return nil, errors.New("Unexpected format of gdrive list, header is: " + header)
+	}
+
+	rows := []listRow{}
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, delim)
+		// some versions print summaries or other noise after the data rows
+		if len(parts) != len(gdriveListColumns) {
+			log("skipping unexpected line in gdrive list: "+line, WARN)
+			continue
+		}
+		date, err := parseDate(parts[4])
+		if err != nil {
+			log("skipping line with an invalid date in gdrive list: "+line, WARN)
+			continue
+		}
+		rows = append(rows, listRow{ID: parts[0], Name: parts[1], Type: parts[2], Size: parts[3], Date: date})
+	}
+	return rows, nil
+}
+
+// parseList replaces the folder's children with what's in the output of gdrive files list
+// (delimited or JSON), or rclone lsjson with -remote
+func (f *Folder) parseList(conf *Config, raw string) error {
+	if conf.Remote != "" {
+		return f.parseRcloneList(conf, raw)
+	}
+	// an empty folder still has a header, no output at all means gdrive didn't list anything
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return errors.New("Empty result from gdrive list (not even a header) for folder " + f.fullPath())
+	}
+
+	// JSON is what -gdrive-json asks for, but whatever the format is, it can't be mistaken for the other
+	var rows []listRow
+	var err error
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		rows, err = parseJSONList(raw)
+	} else {
+		rows, err = parseDelimitedList(raw)
+	}
+	if err != nil {
+		return err
+	}
+
+	// re-use folders we already know, so a refresh keeps their cached subtrees
+	cached := map[string]*Folder{}
+	for i := range f.Folders {
+		cached[f.Folders[i].ID] = f.Folders[i]
+	}
+
+	files := []*File{}
+	folders := []*Folder{}
+	skipped := map[string]int{}
+	for _, row := range rows {
+		// excluded types are left out entirely, only their count shows up as skipped
+		if slices.Contains(conf.ExcludeTypes, row.Type) {
+			skipped[row.Type] += 1
+			continue
+		}
+
+		switch row.Type {
+		case "regular":
+			size, err := conf.sizeFromString(row.Size)
+			if err != nil {
+				log("skipping "+row.Name+" in gdrive list: "+err.Error(), WARN)
+				continue
+			}
+			files = append(files, &File{
+				ID:       row.ID,
+				Name:     row.Name,
+				Ext:      filepath.Ext(row.Name),
+				Size:     size,
+				Date:     row.Date,
+				MimeType: row.MimeType,
+				Owner:    row.Owner,
+			})
+
+		case "folder":
+			if folder, ok := cached[row.ID]; ok {
+				folder.Name = row.Name
+				folder.Date = row.Date
+				folders = append(folders, folder)
+				continue
+			}
+			folders = append(folders, &Folder{
+				ID:   row.ID,
+				Name: row.Name,
+				Date: row.Date,
+				save: f.save,
+			})
+
+		case "document":
+			// gdrive may not report a size for them at all, which is as good as 0
+			size, _ := conf.sizeFromString(row.Size)
+			files = append(files, &File{
+				ID:       row.ID,
+				Name:     row.Name,
+				Ext:      filepath.Ext(row.Name),
+				Date:     row.Date,
+				Type:     "document",
+				DocSize:  size,
+				MimeType: row.MimeType,
+				Owner:    row.Owner,
+			})
+
+		case "shortcut":
+			// they don't take up space, but we keep count so it's clear they aren't included
+			skipped[row.Type] += 1
+
+		default:
+			// Drive has more types than we know of, they are listed but don't add to the size
+			log("unknown type "+row.Type+" of "+row.Name+", counted as 0 bytes", DEBUG)
+			files = append(files, &File{
+				ID:       row.ID,
+				Name:     row.Name,
+				Ext:      filepath.Ext(row.Name),
+				Date:     row.Date,
+				Type:     row.Type,
+				MimeType: row.MimeType,
+				Owner:    row.Owner,
+			})
+		}
+	}
+
+	f.Files = files
+	f.Streamed = false
+	f.own = nil
+	f.Folders = folders
+	f.Skipped = skipped
+	f.markScanned(conf)
+	f.markDirty()
+
+	return nil
+}
+
+const folderMime = "application/vnd.google-apps.folder"
+
+// Info looks up a single folder by ID. It is a cheap way to check that an ID
+// exists and is accessible before scanning it, a typo otherwise just looks empty.
+func Info(conf *Config, id string) (*Folder, error) {
+	raw, err := sh(conf.gdrive("files", "info", id)...)
+	if err != nil || strings.TrimSpace(raw) == "" {
+		return nil, errors.New("folder ID not found or not accessible: " + id)
+	}
+
+	// gdrive prints one "Key: value" per line
+	fields := map[string]string{}
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if mime, ok := fields["Mime"]; ok && mime != folderMime {
+		return nil, errors.New("ID is not a folder: " + id)
+	}
+
+	return &Folder{ID: id, Name: fields["Name"]}, nil
+}
+
+// shCalls counts all backend calls, to tell how many a scan made
+var shCalls atomic.Int64
+
+func sh(parts ...string) (string, error) {
+	return shContext(context.Background(), parts...)
+}
+
+// shContext is sh, but the command is killed once ctx is done
+func shContext(ctx context.Context, parts ...string) (string, error) {
+	log("sh> "+strings.Join(parts, " "), DEBUG)
+	shCalls.Add(1)
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	// children of a killed command may still hold on to its output, don't wait for them
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.New("failed to start command: " + err.Error())
+	}
+
+	if stderr.Len() > 0 {
+		log("gdrive: "+strings.TrimSpace(stderr.String()), ERROR)
+	}
+
+	return stdout.String(), nil
+}
+
+// unitBase is what a kb is in bytes, 1024 unless -si says 1000.
+// It applies to parsing and showing sizes alike, so both always agree.
+func (c *Config) unitBase() float64 {
+	if c.SI {
+		return 1000
+	}
+	return 1024
+}
+
+// sizeFromString parses sizes like gdrive prints them ("12", "1.5 MB"),
+// as well as the compact form used in flags ("1.5mb").
+//
+// Supported numbers are integers ("1234567"), integers with thousands
+// separators ("1,234,567"), floats ("1.5") and floats with an exponent ("1.2e6").
+// Supported units are b, kb, mb, gb, tb in any case, and no unit for bytes.
+func (c *Config) sizeFromString(s string) (int, error) {
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	unit := strings.TrimSpace(s[len(num):])
+	num = strings.ReplaceAll(strings.TrimSpace(num), ",", "")
+
+	if unit == "" {
+		if res, err := strconv.Atoi(num); err == nil {
+			return res, nil
+		}
+	}
+
+	res, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, errors.New("Failed to parse as size: " + s)
+	}
+	if unit == "" {
+		return int(res), nil
+	}
+	base := c.unitBase()
+	switch strings.ToLower(unit) {
+	case "b":
+		return int(res), nil
+	case "kb":
+		return int(res * base), nil
+	case "mb":
+		return int(res * base * base), nil
+	case "gb":
+		return int(res * base * base * base), nil
+	case "tb":
+		return int(res * base * base * base * base), nil
+	// binary units are unambiguous, whatever -si says
+	case "kib":
+		return int(res * 1024), nil
+	case "mib":
+		return int(res * 1024 * 1024), nil
+	case "gib":
+		return int(res * 1024 * 1024 * 1024), nil
+	case "tib":
+		return int(res * 1024 * 1024 * 1024 * 1024), nil
+	}
+	return 0, errors.New("Failed to parse as size: " + s)
+}
+
+// formatSize shows sizes with one decimal in the largest unit that keeps them
+// below 1000 or 1024 after rounding, so 1048575 bytes show as 1.0mb, not 1024.0kb.
+func (c *Config) formatSize(i int64) string {
+	if i == 0 {
+		return ""
+	}
+
+	base := c.unitBase()
+	if float64(i) < base {
+		return fmt.Sprintf("%d", i) + "b"
+	}
+
+	f := float64(i) / base
+	for _, unit := range []string{"kb", "mb", "gb"} {
+		if math.Round(f*10)/10 < base {
+			return fmt.Sprintf("%.1f%s", f, unit)
+		}
+		f = f / base
+	}
+	return fmt.Sprintf("%.1ftb", f)
+}
+
+// formatCount adds thousands separators, e.g. 1234567 becomes 1,234,567
+func formatCount(n int64) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func parseDate(s string) (int64, error) {
+	time, err := time.Parse("2006-01-02 15:04:05", s)
+	if err != nil {
+		return 0, errors.New("Failed to parse as time: " + s)
+	}
+	return time.Unix(), nil
+}
+
+// rebuild computes the aggregates of the whole subtree
+func (f *Folder) rebuild(conf *Config) {
+	for i := range f.Folders {
+		f.attachChild(f.Folders[i])
+		f.Folders[i].rebuild(conf)
+	}
+	f.sum(conf)
+}
+
+// sum computes the folder's aggregates from its files and the aggregates its subfolders already have
+func (f *Folder) sum(conf *Config) {
+	f.size = 0
+	f.directSize = 0
+	f.estimated = 0
+	f.folderIdx = map[string]*Folder{}
+	f.unknown = 0
+	f.known = 0
+	f.unscanned = 0
+	f.staleTotal = 0
+	f.files = 0
+	f.folders = len(f.Folders)
+	f.skipped = map[string]int{}
+	for kind, n := range f.Skipped {
+		f.skipped[kind] += n
+	}
+
+	for i := range f.Folders {
+		folder := f.Folders[i]
+		for kind, n := range folder.skipped {
+			f.skipped[kind] += n
+		}
+		f.files += folder.files
+		f.folders += folder.folders
+		f.unscanned += folder.unscanned
+		if folder.LastUpdate == 0 {
+			f.unscanned += 1
+		}
+		// Drive allows siblings with the same name, the first one wins
+		if _, ok := f.folderIdx[pathName(folder.Name)]; !ok {
+			f.folderIdx[pathName(folder.Name)] = folder
+		}
+		f.size += folder.size
+		f.estimated += folder.estimated
+		f.staleTotal += folder.staleTotal
+		if folder.LastUpdate < conf.tooOldFor(folder) {
+			f.staleTotal += 1
+			f.unknown += 1
+		} else {
+			f.known += 1
+		}
+	}
+
+	// streamed files are only read once, they don't change until the folder is fetched again
+	own := f.own
+	if own == nil {
+		res := sumFiles(conf, f.fileList(conf))
+		own = &res
+		if f.Streamed {
+			f.own = own
+		}
+	}
+	f.files += own.files
+	f.size += own.size
+	f.directSize = own.size
+	f.estimated += own.estimated
+	if own.documents > 0 {
+		f.skipped["document"] += own.documents
+	}
+}
+
+// ownFiles is what the files directly in a folder add to its aggregates
+type ownFiles struct {
+	files     int   // that count, within -newer-than/-older-than
+	documents int   // that are skipped because they have no size
+	size      int64 // in bytes, with estimates
+	estimated int64 // part of size that is only estimated
+}
+
+// sumFiles adds up the files directly in a folder and sets what is derived from them, like their Ext
+func sumFiles(conf *Config, files []*File) ownFiles {
+	res := ownFiles{}
+	for i := range files {
+		file := files[i]
+		file.Ext = filepath.Ext(file.Name)
+		// files outside of -newer-than/-older-than don't count at all
+		if !conf.inDateRange(file.Date) {
+			continue
+		}
+		res.files += 1
+		file.estimated = false
+		if file.Type == "document" {
+			if conf.CountDocs {
+				file.Size = file.DocSize
+			} else if conf.EstimateDocSize {
+				file.Size = int(conf.docEstimate(file))
+				file.estimated = true
+				res.estimated += int64(file.Size)
+			} else {
+				file.Size = 0
+				res.documents += 1
+			}
+		}
+		res.size += int64(file.Size)
+	}
+	return res
+}
+
+type goDeep struct {
+	max      int
+	cur      int
+	onUpdate func(f *Folder)
+
+	fetched  int       // folders actually fetched, the others were fresh
+	depth    int       // of the folder currently being scanned, relative to where the scan started
+	unsaved  int       // folders fetched since the last checkpoint
+	lastSave time.Time // of the last checkpoint
+	started  time.Time // when the scan started
+	calls    int64     // backend calls made before the scan started
+
+	ctx context.Context // stops the scan once it's done, e.g. after -scan-timeout. nil for never.
+}
+
+// errScanTimeout stops a deep scan gracefully, what it fetched until then is kept
+var errScanTimeout = errors.New("scan stopped by -scan-timeout, folders it didn't get to stay unscanned")
+
+// deep scans save a checkpoint of the cache every so often instead of after every
+// folder, so a crash loses at most what was fetched since the last one
+const autoSaveInterval = 30 * time.Second
+const autoSaveFolders = 50
+
+// saveDue counts a fetched folder and reports if it's time for a checkpoint
+func (d *goDeep) saveDue() bool {
+	d.unsaved += 1
+	return d.unsaved >= autoSaveFolders || time.Since(d.lastSave) >= autoSaveInterval
+}
+
+func (d *goDeep) checkpoint(save func() error) error {
+	if err := save(); err != nil {
+		return errors.New("failed to save cache: " + err.Error())
+	}
+	d.unsaved = 0
+	d.lastSave = time.Now()
+	return nil
+}
+
+// updateTree runs changes to the tree. The TUI points it to its own goroutine, so scans
+// in the background never change the tree while it is drawn or changed by a key.
+var updateTree = func(fn func()) { fn() }
+
+// ensureData fetches the folder if its data is stale (or forced), and its subfolders too if goDeep is set.
+// A failed fetch stops the scan, but everything fetched until then is kept.
+//
+// In a deep scan, fresh folders aren't fetched again but their subfolders are still visited.
+// That way an interrupted scan continues where it stopped: what it fetched is fresh, and
+// what it only discovered has never been fetched (LastUpdate 0).
+func (f *Folder) ensureData(conf *Config, forceUpdate bool, goDeep *goDeep) error {
+	var fresh bool
+	var path string
+	updateTree(func() {
+		fresh = !forceUpdate && f.LastUpdate > conf.tooOldFor(f)
+		path = f.fullPath()
+	})
+	if fresh && goDeep == nil {
+		return nil
+	}
+	// the scan starts with the fetch of this folder, however many calls that takes
+	if goDeep != nil && goDeep.depth == 0 {
+		goDeep.started = time.Now()
+		goDeep.calls = shCalls.Load()
+	}
+
+	if !fresh {
+		ctx := context.Background()
+		if goDeep != nil && goDeep.ctx != nil {
+			ctx = goDeep.ctx
+		}
+		if ctx.Err() != nil {
+			return errScanTimeout
+		}
+		if err := f.getFiles(ctx, conf); err != nil {
+			// a fetch that was cut off by the timeout is just not done, it didn't fail
+			if ctx.Err() != nil {
+				return errScanTimeout
+			}
+			return errors.New("failed to fetch " + path + ": " + err.Error())
+		}
+		if f.save == nil {
+			panic("Reached a folder without a save function: " + path)
+		}
+
+		var event progressEvent
+		var err error
+		updateTree(func() {
+			event = progressEvent{Event: "folder", Path: path, Files: len(f.Files), Folders: len(f.Folders)}
+			if goDeep != nil {
+				goDeep.fetched += 1
+				event.Done, event.Total = goDeep.cur, goDeep.max+f.scannable(conf)
+				// what a deep scan fetches isn't kept in memory with -stream-to, it may be too much
+				if conf.StreamTo != "" {
+					err = f.streamOut(conf)
+				}
+			}
+			if err != nil {
+				return
+			}
+			if goDeep == nil {
+				if saveErr := f.save(); saveErr != nil {
+					err = errors.New("failed to save cache: " + saveErr.Error())
+				}
+			} else if goDeep.saveDue() {
+				err = goDeep.checkpoint(f.save)
+			}
+		})
+		progress(event)
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if goDeep != nil {
+		var folders []*Folder
+		updateTree(func() {
+			goDeep.max += f.scannable(conf)
+			// the UI may take folders out while the scan runs, e.g. when they are deleted
+			folders = slices.Clone(f.Folders)
+		})
+		goDeep.depth += 1
+
+		rebuilt := false
+		for _, folder := range folders {
+			ignored := false
+			updateTree(func() {
+				f.attachChild(folder)
+				ignored = conf.Ignore.matches(folder.fullPath(), true)
+			})
+			if ignored {
+				continue
+			}
+			fetched := goDeep.fetched
+			err = folder.ensureData(conf, forceUpdate, goDeep)
+			updateTree(func() { f.rebuild(conf) })
+			rebuilt = true
+			// walking through what's already fresh doesn't need to be shown
+			if goDeep.fetched != fetched {
+				goDeep.onUpdate(f)
+			}
+			if err != nil {
+				break
+			}
+		}
+		// without any subfolder that was scanned, what was fetched here isn't counted yet
+		if !rebuilt {
+			updateTree(func() { f.rebuild(conf) })
+		}
 
-	all := []*Folder{&res}
-	for i := 0; i < len(all); i++ {
-		cur := all[i]
-		all = append(all, cur.Folders...)
-		cur.save = func() error {
-			return save(&res)
+		// whatever the scan fetched since the last checkpoint is saved once it's done, even if it failed
+		goDeep.depth -= 1
+		if goDeep.depth == 0 && goDeep.unsaved > 0 {
+			var saveErr error
+			updateTree(func() { saveErr = goDeep.checkpoint(f.save) })
+			if saveErr != nil && err == nil {
+				err = saveErr
+			}
+		}
+
+		goDeep.cur += 1
+		if goDeep.depth == 0 {
+			took := time.Since(goDeep.started)
+			calls := shCalls.Load() - goDeep.calls
+			log(fmt.Sprintf("scanned %s folders in %s (%s API calls)", formatCount(int64(goDeep.fetched)),
+				took.Round(time.Second), formatCount(calls)), INFO)
+			progress(progressEvent{Event: "done", Path: path, Folders: goDeep.fetched, Calls: calls, Seconds: took.Seconds()})
+		}
+		if path == "" {
+			log("empty path on entry: "+f.ID, DEBUG)
 		}
+		log(fmt.Sprintf("progress: %s %d/%d %s", progressbar(float64(goDeep.cur)/float64(goDeep.max), 30, conf.barRunes()), goDeep.cur, goDeep.max, path), INFO)
+
+	} else {
+		log("rebuilding idx...", DEBUG)
+		updateTree(func() { f.rebuild(conf) })
 	}
-	res.path = "/"
-	res.rebuild()
 
-	return &res, err
+	updateTree(func() { f.propagate(conf) })
+	return err
 }
 
-const MAX_COUNT = 500
-
-func (f *Folder) getFiles() error {
-	cmd := []string{"gdrive", "files", "list", "--field-separator", delim, "--max", strconv.Itoa(MAX_COUNT)}
-	if f.ID != "" {
-		cmd = append(cmd, "--parent", f.ID)
+// propagate sums up the aggregates of all parents again, after this folder changed
+func (f *Folder) propagate(conf *Config) {
+	for parent := f.parent; parent != nil; parent = parent.parent {
+		parent.sum(conf)
 	}
+}
 
-	raw, err := sh(cmd...)
-	if err != nil {
-		return err
+// applyList updates the folder with a listing that was fetched separately, e.g. in the
+// background, so the tree is only touched by whoever calls this
+func (f *Folder) applyList(conf *Config, raw string) error {
+	if err := f.parseList(conf, raw); err != nil {
+		return errors.New("failed to fetch " + f.fullPath() + ": " + err.Error())
 	}
-
-	lines := strings.Split(string(raw), "\n")
-	header := lines[0]
-	if header != gdriveListHeader {
-		return errors.New("Unexpected header in gdrive list: " + header)
+	f.rebuild(conf)
+	f.propagate(conf)
+	if err := f.save(); err != nil {
+		return errors.New("failed to save cache: " + err.Error())
 	}
+	return nil
+}
 
-	for i := 1; i < len(lines); i++ {
-		line := lines[i]
-		if line == "" {
-			continue
+// nextStale finds the closest folder to root that was scanned before but is stale by now.
+// Folders that were never scanned are left alone, so this doesn't slowly crawl the whole drive.
+func nextStale(conf *Config, root *Folder) *Folder {
+	all := []*Folder{root}
+	for i := 0; i < len(all); i++ {
+		cur := all[i]
+		if cur.LastUpdate != 0 && cur.LastUpdate < conf.tooOldFor(cur) {
+			return cur
 		}
-
-		parts := strings.Split(line, delim)
-		switch parts[2] {
-		case "regular":
-			f.Files = append(f.Files, &File{
-				ID:   parts[0],
-				Name: parts[1],
-				Ext:  filepath.Ext(parts[1]),
-				Size: parseSize(parts[3]),
-				Date: parseDate(parts[4]),
-			})
-
-		case "folder":
-			f.Folders = append(f.Folders, &Folder{
-				ID:   parts[0],
-				Name: parts[1],
-				Date: parseDate(parts[4]),
-				save: f.save,
-			})
-
-		case "document":
-			// ignore it
-		case "shortcut":
-			// ignore that too
-
-		default:
-			panic("unknown type of file: " + parts[2])
+		for j := range cur.Folders {
+			if !conf.Ignore.matches(cur.Folders[j].fullPath(), true) {
+				all = append(all, cur.Folders[j])
+			}
 		}
 	}
-
-	f.LastUpdate = time.Now().Unix()
-
 	return nil
 }
 
-func sh(parts ...string) (string, error) {
-	log("sh> "+strings.Join(parts, " "), DEBUG)
-	cmd := exec.Command(parts[0], parts[1:]...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		return "", errors.New("failed to start command: " + err.Error())
-	}
-
-	if stderr.Len() > 0 {
-		log("ERROR: "+stderr.String(), ERROR)
+// scannable is how many subfolders a deep scan goes into, excluded ones are left out
+func (f *Folder) scannable(conf *Config) int {
+	res := 0
+	for i := range f.Folders {
+		if !conf.Ignore.matches(filepath.Join(f.fullPath(), pathName(f.Folders[i].Name)), true) {
+			res += 1
+		}
 	}
-
-	return stdout.String(), nil
+	return res
 }
 
-func parseSize(s string) int {
-	parts := strings.Split(s, " ")
-	if len(parts) == 1 {
-		res, err := strconv.Atoi(s)
-		if err != nil {
-			panic("Failed to parse as size: " + s)
-		}
-		return res
+// atPathColumn finds the folder whose name is at the given column of fullPath,
+// i.e. this folder or one of its parents, nil if the column is outside of it
+func (f *Folder) atPathColumn(col int) *Folder {
+	path := f.fullPath()
+	if col < 0 || col >= runewidth.StringWidth(path) {
+		return nil
 	}
 
-	if len(parts) != 2 {
-		panic("Failed to parse size: " + s)
+	// every slash before the column goes one level deeper, the root is the first one
+	depth, width := 0, 0
+	for _, r := range path {
+		if width >= col {
+			break
+		}
+		if r == '/' {
+			depth += 1
+		}
+		width += runewidth.RuneWidth(r)
 	}
 
-	res, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
-		panic("Failed to parse as size: " + s)
+	chain := []*Folder{}
+	for cur := f; cur != nil; cur = cur.parent {
+		chain = append(chain, cur)
 	}
-	switch strings.ToLower(parts[1]) {
-	case "b":
-		return int(res)
-	case "kb":
-		return int(res * 1024)
-	case "mb":
-		return int(res * 1024 * 1024)
-	case "gb":
-		return int(res * 1024 * 1024 * 1024)
-	case "tb":
-		return int(res * 1024 * 1024 * 1024 * 1024)
+	if depth >= len(chain) {
+		return nil
 	}
-	panic("Failed to parse as size: " + s)
+	return chain[len(chain)-1-depth]
 }
 
-func formatSize(i int64) string {
-	if i == 0 {
-		return ""
-	}
-
-	if i < 1024 {
-		return fmt.Sprintf("%d", i) + "b"
+// within reports if the folder is top or somewhere below it
+func (f *Folder) within(top *Folder) bool {
+	for cur := f; cur != nil; cur = cur.parent {
+		if cur == top {
+			return true
+		}
 	}
+	return false
+}
 
-	f := float32(i / 1024)
-	if f < 1024 {
-		return fmt.Sprintf("%.1fkb", f)
-	}
+// markScanned records that the folder's data was just fetched, and by whom
+func (f *Folder) markScanned(conf *Config) {
+	f.LastUpdate = time.Now().Unix()
+	f.LastScanBy = conf.ScannerName
+}
 
-	f = f / 1024
-	if f < 1024 {
-		return fmt.Sprintf("%.1fmb", f)
+// depth is how many folders are above this one, 0 for the root
+func (f *Folder) depth() int {
+	res := 0
+	for cur := f.parent; cur != nil; cur = cur.parent {
+		res++
 	}
+	return res
+}
 
-	f = f / 1024
-	if f < 1024 {
-		return fmt.Sprintf("%.1fgb", f)
+// markDirty flags the tree this folder belongs to as changed, so the next save writes it
+func (f *Folder) markDirty() {
+	root := f
+	for root.parent != nil {
+		root = root.parent
 	}
+	root.dirty = true
+}
 
-	f = f / 1024
-	return fmt.Sprintf("%.1ftb", f)
+func (f *Folder) attachChild(child *Folder) {
+	child.parent = f
+	child.path = f.fullPath()
 }
 
-func parseDate(s string) int64 {
-	time, err := time.Parse("2006-01-02 15:04:05", s)
-	if err != nil {
-		panic("Failed to parse as time: " + s)
+// walkFiles calls fn for every file in the subtree that isn't ignored, with its full path
+func (f *Folder) walkFiles(conf *Config, fn func(path string, file *File)) {
+	all := []*Folder{f}
+	for i := 0; i < len(all); i++ {
+		cur := all[i]
+		for j := range cur.Folders {
+			folder := cur.Folders[j]
+			if !conf.Ignore.matches(folder.fullPath(), true) {
+				all = append(all, folder)
+			}
+		}
+
+		files := cur.fileList(conf)
+		for j := range files {
+			file := files[j]
+			path := cur.filePath(file)
+			if !conf.hidesFile(path, file) {
+				fn(path, file)
+			}
+		}
 	}
-	return time.Unix()
 }
 
-func (f *Folder) rebuild() {
-	f.size = 0
-	f.folderIdx = map[string]*Folder{}
-	f.fileIdx = map[string]*File{}
-	f.unknown = 0
-	f.known = 0
+// FindByPath resolves a full path like /foo/bar, relative to root, to its folder.
+// Empty segments (e.g. from trailing or double slashes) are skipped.
+func FindByPath(root *Folder, path string) (*Folder, bool) {
+	f, missing := resolvePath(root, path)
+	return f, missing == ""
+}
 
-	for i := range f.Folders {
-		folder := f.Folders[i]
-		f.attachChild(folder)
-		folder.rebuild()
-		f.folderIdx[folder.Name] = folder
-		f.size += folder.size
-		if folder.LastUpdate < tooOld {
-			f.unknown += 1
+// resolvePath follows a full path like FindByPath, as far as it exists. It returns the
+// deepest folder it got to and the first segment that wasn't found, empty if none.
+func resolvePath(root *Folder, path string) (*Folder, string) {
+	cur := root
+	for _, name := range strings.Split(path, "/") {
+		if name == "" || name == "." {
+			continue
+		}
+
+		var next *Folder
+		if cur.folderIdx != nil {
+			next = cur.folderIdx[name]
 		} else {
-			f.known += 1
+			for i := range cur.Folders {
+				if pathName(cur.Folders[i].Name) == name {
+					next = cur.Folders[i]
+					break
+				}
+			}
 		}
+		if next == nil {
+			return cur, name
+		}
+		cur = next
 	}
+	return cur, ""
+}
 
-	for i := range f.Files {
-		file := f.Files[i]
-		f.size += int64(file.Size)
-	}
+func (f *Folder) fullPath() string {
+	return filepath.Join(f.path, pathName(f.Name))
 }
 
-type goDeep struct {
-	max      int
-	cur      int
-	onUpdate func(f *Folder)
+// filePath is the full path of a file in this folder
+func (f *Folder) filePath(file *File) string {
+	return filepath.Join(f.fullPath(), pathName(file.Name))
 }
 
-func (f *Folder) ensureData(forceUpdate bool, goDeep *goDeep) {
-	if !forceUpdate && f.LastUpdate > tooOld {
-		return
+// pathName makes a Drive name safe to use as a path segment. Drive allows
+// slashes and names like "..", which would otherwise change what a path points to.
+// Name itself stays as it is for display.
+func pathName(name string) string {
+	name = strings.ReplaceAll(name, "/", "_")
+	if name == "." || name == ".." {
+		return "_" + name
 	}
+	return name
+}
 
-	oldSize := f.size
+var skippedLabels = map[string]string{
+	"document": "Google Docs",
+	"shortcut": "shortcuts",
+}
 
-	if err := f.getFiles(); err != nil {
-		panic(err)
-	}
-	if f.save == nil {
-		panic("Reached a folder without a save function: " + f.path)
+// skippedNote says which entries in this subtree are not part of its size
+func (f *Folder) skippedNote() string {
+	kinds := make([]string, 0, len(f.skipped))
+	for kind, n := range f.skipped {
+		if n > 0 {
+			kinds = append(kinds, kind)
+		}
 	}
-	if err := f.save(); err != nil {
-		panic(err)
+	if len(kinds) == 0 {
+		return ""
 	}
+	sort.Strings(kinds)
 
-	if goDeep != nil {
-		goDeep.max += len(f.Folders)
-
-		for i := range f.Folders {
-			folder := f.Folders[i]
-			f.attachChild(folder)
-			folder.ensureData(forceUpdate, goDeep)
-			f.rebuild()
-			goDeep.onUpdate(f)
-		}
-		// otherwise it never gets rebuilt vv
-		if len(f.Folders) == 0 {
-			f.rebuild()
-		}
-
-		goDeep.cur += 1
-		if f.path == "" {
-			log(fmt.Sprintf("empty path on entry: %#v", f), DEBUG)
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		label, ok := skippedLabels[kind]
+		if !ok {
+			label = kind
 		}
-		log(fmt.Sprintf("progress: %s %d/%d %s", progressbar(float64(goDeep.cur)/float64(goDeep.max), 30), goDeep.cur, goDeep.max, f.path), INFO)
-
-	} else {
-		log("rebuilding idx...", DEBUG)
-		f.rebuild()
+		parts[i] = strconv.Itoa(f.skipped[kind]) + " " + label
 	}
+	return " (" + strings.Join(parts, ", ") + " not counted)"
+}
 
-	sizeChange := (f.size - oldSize)
-	for parent := f.parent; parent != nil; parent = parent.parent {
-		parent.size += sizeChange
-		parent.unknown -= 1
-		parent.known += 1
+// staleCount is how many folders with stale (or no) data are below this one for -stale-badge,
+// only its direct subfolders or all of them. 0 without a badge.
+func (f *Folder) staleCount(conf *Config) int {
+	switch conf.StaleBadge {
+	case "direct":
+		return f.unknown
+	case "total":
+		return f.staleTotal
 	}
+	return 0
 }
 
-func (f *Folder) attachChild(child *Folder) {
-	child.parent = f
-	child.path = filepath.Join(f.path, f.Name)
+// sizeLabel marks sizes with a trailing ~ if they miss data of subfolders that were never scanned.
+// Data that was scanned a while ago is still real data, it doesn't count as missing.
+func (f *Folder) sizeLabel(conf *Config) string {
+	if f.unscanned > 0 {
+		return conf.formatSize(f.size) + "~"
+	}
+	return conf.formatSize(f.size)
 }
 
-func (f *Folder) explorer(list *tview.List, folderChanged bool, selectFn func(*Folder)) []*Folder {
+func (f *Folder) explorer(conf *Config, list *tview.List, v view, folderChanged bool, selectFn func(*Folder)) []entry {
 	// position in the list, either where we are if it's a refresh, or where we were last in this folder
 	var last int
 	if folderChanged {
@@ -553,100 +2277,401 @@ func (f *Folder) explorer(list *tview.List, folderChanged bool, selectFn func(*F
 
 	list.Clear()
 
-	// we need a copy so we can sort it without breaking
-	res := make([]*Folder, len(f.Folders))
-	copy(res, f.Folders)
+	files := f.fileList(conf)
+	entries := make([]entry, 0, len(f.Folders)+len(files))
+	for i := range f.Folders {
+		if !conf.Ignore.matches(f.Folders[i].fullPath(), true) {
+			entries = append(entries, entry{folder: f.Folders[i]})
+		}
+	}
+	for i := range files {
+		if !conf.hidesFile(f.filePath(files[i]), files[i]) {
+			entries = append(entries, entry{file: files[i]})
+		}
+	}
 
-	sort.Slice(res, func(i, j int) bool {
-		a := res[i]
-		b := res[j]
-		if a.size != b.size {
-			return a.size > b.size
+	sort.SliceStable(entries, func(i, j int) bool {
+		a := entries[i]
+		b := entries[j]
+		if v.dirsFirst && (a.folder != nil) != (b.folder != nil) {
+			return a.folder != nil
 		}
-		return res[i].Name < res[j].Name
+		return less(v.sortBy, a.name(), b.name(), v.sizeOf(a), v.sizeOf(b), a.date(), b.date())
 	})
 
-	offset := 1
-	if f.parent != nil {
-		list.AddItem(fmt.Sprintf("%+8s %s [blue]%s", "", "", ".."),
+	// the size column is as wide as the widest size in this folder
+	sizeWidth := 1
+	for i := range entries {
+		sizeWidth = max(sizeWidth, len(v.sizeLabel(conf, entries[i])))
+	}
+
+	total := f.size
+	if v.direct {
+		total = 0
+		for i := range entries {
+			total += v.sizeOf(entries[i])
+		}
+	}
+	if v.ofLargest {
+		total = 0
+		for i := range entries {
+			total = max(total, v.sizeOf(entries[i]))
+		}
+	}
+	if v.ofRoot {
+		total = v.root.size
+	}
+	barWidth := v.barWidth()
+	if v.ofQuota {
+		total = conf.Quota
+	}
+	if v.showPct() {
+		barWidth += 7
+	}
+	// whatever is left after the size and bar columns is for the name, 0 means no limit
+	nameWidth := 0
+	if v.width > 0 {
+		nameWidth = max(v.width-sizeWidth-barWidth-2-len(v.markLabel(entry{})), 8)
+	}
+
+	// only now, so hidden entries still count towards the totals above
+	switch v.only {
+	case "files":
+		entries = slices.DeleteFunc(entries, func(e entry) bool { return e.folder != nil })
+	case "folders":
+		entries = slices.DeleteFunc(entries, func(e entry) bool { return e.file != nil })
+	}
+
+	// the long tail of small entries is collapsed into one row, until that is expanded
+	var other []entry
+	if v.otherBelow > 0 && !f.showOther && total > 0 {
+		keep := make([]entry, 0, len(entries))
+		for i := range entries {
+			if float64(v.sizeOf(entries[i]))/float64(total) < v.otherBelow {
+				other = append(other, entries[i])
+			} else {
+				keep = append(keep, entries[i])
+			}
+		}
+		// collapsing a single entry would just hide it
+		if len(other) > 1 {
+			entries = keep
+		} else {
+			other = nil
+		}
+	}
+
+	// huge folders only show their largest entries, the list gets slow otherwise
+	var hidden []entry
+	if conf.MaxItems > 0 && len(entries) > conf.MaxItems {
+		bySize := slices.Clone(entries)
+		sort.SliceStable(bySize, func(i, j int) bool { return v.sizeOf(bySize[i]) > v.sizeOf(bySize[j]) })
+		shown := map[entry]bool{}
+		for _, e := range bySize[:conf.MaxItems] {
+			shown[e] = true
+		}
+		hidden = bySize[conf.MaxItems:]
+		entries = slices.DeleteFunc(entries, func(e entry) bool { return !shown[e] })
+	}
+
+	// one entry per list item, the empty entry stands for .., other and hidden items
+	rows := []entry{}
+
+	if f.parent != nil && f != v.top {
+		list.AddItem(fmt.Sprintf("%s%*s %*s %s%s", v.markLabel(entry{}), sizeWidth, "", barWidth, "", conf.tag("blue"), ".."),
 			"", 0, func() {
 				f.lastIdx = list.GetCurrentItem()
 				selectFn(f.parent)
 			})
-		offset += 1
+		rows = append(rows, entry{})
 	}
 
-	for i := range res {
-		folder := res[i]
+	for i := range entries {
+		e := entries[i]
 		var progress float64
-		if f.size >= 1 {
-			progress = float64(folder.size) / float64(f.size)
+		if total >= 1 {
+			progress = float64(v.sizeOf(e)) / float64(total)
+		}
+		bar := progressbar(min(progress, 1), v.barWidth(), conf.barRunes())
+		if v.showPct() {
+			bar += fmt.Sprintf(" %5.1f%%", progress*100)
+		}
+
+		// space hogs stand out
+		sizeTag, fileTag, folderTag := conf.tag("orange::b"), "", conf.tag("blue::b")
+		if conf.Highlight > 0 && v.sizeOf(e) >= conf.Highlight {
+			sizeTag, fileTag, folderTag = conf.tag("red::b"), conf.tag("red::b"), conf.tag("red::b")
+		}
+
+		if e.folder == nil {
+			text := fmt.Sprintf("%s%s%*s %s%s %s%s",
+				v.markLabel(e), sizeTag, sizeWidth, v.sizeLabel(conf, e),
+				conf.tag("white"), bar,
+				fileTag, tview.Escape(truncate(e.name(), nameWidth)),
+			)
+			list.AddItem(text, "", 0, nil)
+			rows = append(rows, e)
+			continue
+		}
+
+		folder := e.folder
+		// folders that were never scanned are marked in the gap before their name
+		scanned := " "
+		if folder.LastUpdate == 0 {
+			scanned = "▸"
+		}
+		badge := ""
+		if n := folder.staleCount(conf); n > 0 {
+			badge = fmt.Sprintf(" (%d stale)", n)
 		}
-		text := fmt.Sprintf("[orange::b]%+8s [white]%10s [blue::b]%s",
-			formatSize(folder.size),
-			progressbar(progress, 10),
-			tview.Escape(folder.Name+"/"),
+		width := nameWidth - 1
+		if nameWidth > 0 {
+			width = max(nameWidth-1-len(badge), 8)
+		}
+		text := fmt.Sprintf("%s%s%*s %s%s%s%s%s%s%s",
+			v.markLabel(e), sizeTag, sizeWidth, v.sizeLabel(conf, e),
+			conf.tag("white"), bar, scanned,
+			folderTag,
+			tview.Escape(truncate(folder.Name, width)+"/"),
+			conf.tag("gray::-"), badge,
 		)
 		list.AddItem(text, "", 0, func() {
 			f.lastIdx = list.GetCurrentItem()
 			selectFn(folder)
 		})
-		res = append(res, folder)
-		// list.SetCellSimple(i+offset, 0, formatSize(folder.size))
-		// list.SetCellSimple(i+offset, 1, progressbar(progress, 10))
-		// list.SetCell(i+offset, 2, tview.NewTableCell(folder.Name).SetTextColor(tcell.ColorBlue))
-	}
-	offset += len(res)
-
-	files := make([]*File, len(f.Files))
-	copy(files, f.Files)
-	sort.Slice(files, func(i, j int) bool {
-		a := files[i]
-		b := files[j]
-		if a.Size != b.Size {
-			return a.Size > b.Size
-		}
-		return files[i].Name < files[j].Name
-	})
+		rows = append(rows, e)
+	}
 
-	for i := range files {
-		file := files[i]
-		var progress float64
-		if f.size >= 1 {
-			progress = float64(file.Size) / float64(f.size)
+	if len(other) > 0 {
+		var size int64
+		for i := range other {
+			size += v.sizeOf(other[i])
 		}
-		text := fmt.Sprintf("[orange::b]%+8s [white]%10s %s",
-			formatSize(int64(file.Size)),
-			progressbar(progress, 10),
-			tview.Escape(file.Name),
+		bar := progressbar(min(float64(size)/float64(total), 1), v.barWidth(), conf.barRunes())
+		if v.showPct() {
+			bar += fmt.Sprintf(" %5.1f%%", float64(size)/float64(total)*100)
+		}
+		text := fmt.Sprintf("%s%*s %s%s %s",
+			v.markLabel(entry{}), sizeWidth, "",
+			conf.tag("white"), bar,
+			tview.Escape(fmt.Sprintf("… %d other items (%s total)", len(other), conf.formatSize(size))),
+		)
+		list.AddItem(text, "", 0, func() {
+			f.showOther = true
+			selectFn(f)
+		})
+		rows = append(rows, entry{})
+	}
+
+	if len(hidden) > 0 {
+		var size int64
+		for i := range hidden {
+			size += v.sizeOf(hidden[i])
+		}
+		text := fmt.Sprintf("%s%*s %*s %s",
+			v.markLabel(entry{}), sizeWidth, "", barWidth, "",
+			tview.Escape(fmt.Sprintf("… and %d more (%s, not shown with -max-items)", len(hidden), conf.formatSize(size))),
 		)
 		list.AddItem(text, "", 0, nil)
-		// list.SetCellSimple(i+offset, 0, formatSize(int64(file.Size)))
-		// list.SetCellSimple(i+offset, 1, progressbar(progress, 10))
-		// list.SetCell(i+offset, 2, tview.NewTableCell(file.Name).SetTextColor(tcell.ColorBlue))
+		rows = append(rows, entry{})
 	}
 
-	max := list.GetItemCount()
-	if max < last {
-		last = max - 1
+	// when entries disappear (e.g. they were removed) we stay at the same index,
+	// which is now the next item, or move up to the previous one if it was the last
+	count := list.GetItemCount()
+	if last >= count {
+		last = count - 1
+	}
+	if last < 0 {
+		last = 0
 	}
 	list.SetCurrentItem(last)
 
-	return res
+	return rows
+}
+
+// view holds everything that changes how the explorer renders a folder
+type view struct {
+	sortBy    string
+	dirsFirst bool
+	ofQuota   bool // proportions relative to the drive quota instead of the folder
+	width     int  // of the list in cells, 0 if unknown
+	marked    marks
+	top       *Folder // where the explorer is rooted, there is no .. above it
+	direct    bool    // folders only count the files directly in them, not their subfolders
+	ofLargest bool    // bars relative to the largest entry instead of the folder
+	// entries smaller than this share of the total are collapsed into one row, 0 to show all
+	otherBelow float64
+	ofRoot     bool    // proportions relative to the whole tree instead of the folder
+	root       *Folder // of the whole tree
+	only       string  // files or folders to list nothing else, empty for everything
+}
+
+// showPct adds percentages to the bars when they aren't relative to the folder,
+// a bar alone says little about a share of something much bigger
+func (v view) showPct() bool {
+	return v.ofQuota || v.ofRoot
+}
+
+func (v view) sizeOf(e entry) int64 {
+	if v.direct && e.folder != nil {
+		return e.folder.directSize
+	}
+	return e.size()
+}
+
+func (v view) sizeLabel(conf *Config, e entry) string {
+	if v.direct && e.folder != nil {
+		return conf.formatSize(e.folder.directSize)
+	}
+	return e.sizeLabel(conf)
+}
+
+// markLabel is the prefix of marked entries, others get blanks of the same width
+// as long as anything is marked at all
+func (v view) markLabel(e entry) string {
+	if len(v.marked) == 0 {
+		return ""
+	}
+	if v.marked.has(e) {
+		return tview.Escape("[x]") + " "
+	}
+	return "    "
+}
+
+// barWidth grows the progress bar with the available space, within reason
+func (v view) barWidth() int {
+	return min(max(10, v.width/6), 30)
+}
+
+// entry is a row in the explorer, either a folder or a file
+type entry struct {
+	folder *Folder
+	file   *File
+}
+
+func (e entry) name() string {
+	if e.folder != nil {
+		return e.folder.Name
+	}
+	if e.file != nil {
+		return e.file.Name
+	}
+	return ".."
+}
+
+// id is the backend ID of the entry, empty for ..
+func (e entry) id() string {
+	if e.folder != nil {
+		return e.folder.ID
+	}
+	if e.file != nil {
+		return e.file.ID
+	}
+	return ""
+}
+
+func (e entry) size() int64 {
+	if e.folder != nil {
+		return e.folder.size
+	}
+	return int64(e.file.Size)
+}
+
+// realSize is the size without estimates, i.e. what deleting the entry would free up
+func (e entry) realSize() int64 {
+	if e.folder != nil {
+		return e.folder.size - e.folder.estimated
+	}
+	if e.file.estimated {
+		return 0
+	}
+	return int64(e.file.Size)
+}
+
+func (e entry) date() int64 {
+	if e.folder != nil {
+		return e.folder.Date
+	}
+	return e.file.Date
+}
+
+func (e entry) sizeLabel(conf *Config) string {
+	if e.folder != nil {
+		return e.folder.sizeLabel(conf)
+	}
+	if e.file.estimated {
+		return "~" + conf.formatSize(int64(e.file.Size))
+	}
+	return conf.formatSize(int64(e.file.Size))
+}
+
+// truncate shortens s to width cells with an ellipsis in the middle, so both the
+// start and the extension of a name stay visible. Wide characters (e.g. CJK) take two cells.
+func truncate(s string, width int) string {
+	if width <= 0 || runewidth.StringWidth(s) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	headWidth := (width - 1) / 2
+	tailWidth := width - 1 - headWidth
+
+	head, w := 0, 0
+	for head < len(runes) && w+runewidth.RuneWidth(runes[head]) <= headWidth {
+		w += runewidth.RuneWidth(runes[head])
+		head++
+	}
+	tail := len(runes)
+	w = 0
+	for tail > head && w+runewidth.RuneWidth(runes[tail-1]) <= tailWidth {
+		w += runewidth.RuneWidth(runes[tail-1])
+		tail--
+	}
+	return string(runes[:head]) + "…" + string(runes[tail:])
+}
+
+var sortOrders = []string{"size", "name", "date"}
+
+// less orders entries by the given sort: largest first, alphabetical, or newest first.
+// Ties are broken by name.
+func less(sortBy string, aName, bName string, aSize, bSize int64, aDate, bDate int64) bool {
+	switch sortBy {
+	case "size":
+		if aSize != bSize {
+			return aSize > bSize
+		}
+	case "date":
+		if aDate != bDate {
+			return aDate > bDate
+		}
+	}
+	return aName < bName
 }
 
 var progressRunes = []rune{' ', '▏', '▎', '▍', '▌', '▋', '▊', '▉', '█'}
 
+// barStyles are the looks progress bars can have, see -bar-style
+var barStyles = map[string][]rune{
+	"blocks": progressRunes,
+	"ascii":  {'-', '#'},
+	"dots":   {' ', '⡀', '⡄', '⡆', '⡇', '⣇', '⣧', '⣷', '⣿'},
+	"arrows": {' ', '›', '»'},
+}
+
 // progress: 0 - 1.0 (100%)
 // width: number of characters
-func progressbar(progress float64, width int) string {
+// runes: from empty to full, everything in between is used for partially filled cells
+func progressbar(progress float64, width int, runes []rune) string {
 	var segPct = 1 / float64(width)
 	var full = int(math.Floor(progress / segPct))
 	var i = 0
 
 	res := make([]rune, width)
 	for ; i < full && i < width; i++ {
-		res[i] = progressRunes[len(progressRunes)-1]
+		res[i] = runes[len(runes)-1]
 	}
 	if i >= width {
 		return string(res)
@@ -654,16 +2679,16 @@ func progressbar(progress float64, width int) string {
 
 	rem := progress - float64(full)*segPct
 	idx := int(math.Round(
-		rem / segPct * float64(len(progressRunes)-1),
+		rem / segPct * float64(len(runes)-1),
 	))
-	if idx >= len(progressRunes) {
+	if idx >= len(runes) {
 		panic(fmt.Sprintf("trying to access a rune that's above max: rem=%f segPct=%f and idx=%d", rem, segPct, idx))
 	}
-	res[i] = progressRunes[idx]
+	res[i] = runes[idx]
 	i++
 
 	for ; i < width; i++ {
-		res[i] = ' '
+		res[i] = runes[0]
 	}
 
 	return string(res)
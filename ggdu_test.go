@@ -0,0 +1,810 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+)
+
+func TestMain(m *testing.M) {
+	// scans log their progress, which would only clutter the output
+	log = func(string, LOG_LEVEL) {}
+	os.Exit(m.Run())
+}
+
+// testConfig parses args like main does, with the cache in a temp dir
+func testConfig(t testing.TB, args ...string) *Config {
+	t.Helper()
+	args = append([]string{"-cache", filepath.Join(t.TempDir(), "db.json")}, args...)
+	conf, err := parseConfig(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conf
+}
+
+// captureLog collects everything that is logged until the test ends
+func captureLog(t *testing.T) *[]string {
+	t.Helper()
+	prev := log
+	lines := []string{}
+	log = func(msg string, level LOG_LEVEL) {
+		lines = append(lines, level.String()+" "+msg)
+	}
+	t.Cleanup(func() { log = prev })
+	return &lines
+}
+
+// gdriveList is what gdrive files list prints for these rows, with the given line ending
+func gdriveList(eol string, rows ...string) string {
+	return gdriveListHeader + eol + strings.Join(rows, eol) + eol
+}
+
+// row is one line of gdrive files list
+func row(cols ...string) string {
+	return strings.Join(cols, delim)
+}
+
+// fakeCommand puts a shell script with the given name on the PATH, it returns the dir it is in
+func fakeCommand(t *testing.T, name string, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+// fakeGdrive puts a gdrive on the PATH that prints the listing of the --parent folder, "root" without one.
+// The listings are <id>.list files in the dir it returns, a missing one fails.
+func fakeGdrive(t *testing.T, listings map[string]string) string {
+	t.Helper()
+	dir := fakeCommand(t, "gdrive", "parent=root\nwhile [ $# -gt 0 ]; do\n\t[ \"$1\" = --parent ] && parent=$2\n\tshift\ndone\n"+
+		"cat \"$(dirname \"$0\")/$parent.list\"\n")
+	for id, raw := range listings {
+		if err := os.WriteFile(filepath.Join(dir, id+".list"), []byte(raw), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// testTree wires up the root like load does, without a cache behind it
+func testTree(conf *Config, root *Folder) *Folder {
+	all := []*Folder{root}
+	for i := 0; i < len(all); i++ {
+		all = append(all, all[i].Folders...)
+		all[i].save = func() error { return nil }
+	}
+	root.path = "/"
+	root.stream = &streamStore{path: streamPath(conf.SavePath)}
+	root.rebuild(conf)
+	return root
+}
+
+// deepScan is what x does in the TUI
+func deepScan(conf *Config, f *Folder) error {
+	return f.ensureData(conf, false, &goDeep{max: 1, onUpdate: func(*Folder) {}})
+}
+
+func TestParseDelimitedListCRLF(t *testing.T) {
+	raw := gdriveList("\r\n",
+		row("id1", "a.txt", "regular", "12 B", "2024-01-02 03:04:05"),
+		row("id2", "sub", "folder", "", "2024-01-02 03:04:06"),
+	)
+	rows, err := parseDelimitedList(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Unix()
+	if len(rows) != 2 || rows[0].Date != want || rows[1].Date != want+1 || rows[1].Name != "sub" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+}
+
+func TestParseDelimitedListInvalidDate(t *testing.T) {
+	logged := captureLog(t)
+	raw := gdriveList("\n",
+		row("id1", "a.txt", "regular", "12 B", "yesterday"),
+		row("id2", "b.txt", "regular", "12 B", "2024-01-02 03:04:05"),
+	)
+	rows, err := parseDelimitedList(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ID != "id2" {
+		t.Errorf("expected only the row with a valid date, got %+v", rows)
+	}
+	if len(*logged) != 1 || !strings.HasPrefix((*logged)[0], "warn ") {
+		t.Errorf("expected a warning about the skipped line, got %q", *logged)
+	}
+}
+
+func TestDeepScanAllSubfoldersExcluded(t *testing.T) {
+	conf := testConfig(t, "-exclude-path", "/skip")
+	fakeGdrive(t, map[string]string{
+		"root": gdriveList("\n",
+			row("a", "a.txt", "regular", "100", "2024-01-02 03:04:05"),
+			row("s", "skip", "folder", "", "2024-01-02 03:04:05"),
+		),
+	})
+	root := testTree(conf, &Folder{})
+	if err := deepScan(conf, root); err != nil {
+		t.Fatal(err)
+	}
+	if root.size != 100 || root.files != 1 {
+		t.Errorf("expected the fetched file to be counted, got size %d and %d files", root.size, root.files)
+	}
+}
+
+func TestFindByPath(t *testing.T) {
+	conf := testConfig(t)
+	photos := &Folder{ID: "photos", Name: "Photos"}
+	year := &Folder{ID: "2024", Name: "2024"}
+	photos.Folders = []*Folder{year}
+	slash := &Folder{ID: "slash", Name: "a/b"}
+	dup1 := &Folder{ID: "dup1", Name: "Docs"}
+	dup2 := &Folder{ID: "dup2", Name: "Docs"}
+	root := &Folder{Folders: []*Folder{photos, slash, dup1, dup2}}
+
+	tests := []struct {
+		path string
+		want *Folder
+	}{
+		{"/", root},
+		{"", root},
+		{"/Photos", photos},
+		{"/Photos/2024", year},
+		{"/Photos/2024/", year},
+		{"Photos//2024", year},
+		{"/./Photos", photos},
+		{"/a_b", slash},
+		{"/Docs", dup1},
+	}
+	// without folderIdx the children are searched, with it they are looked up, both have to agree
+	for _, built := range []bool{false, true} {
+		if built {
+			testTree(conf, root)
+		}
+		for _, tt := range tests {
+			got, ok := FindByPath(root, tt.path)
+			if !ok || got != tt.want {
+				t.Errorf("FindByPath(%q) with index %v = %v, %v, want %v", tt.path, built, got.ID, ok, tt.want.ID)
+			}
+		}
+	}
+
+	for _, path := range []string{"/Music", "/Photos/2023", "/a/b", "/Photos/2024/x"} {
+		if _, ok := FindByPath(root, path); ok {
+			t.Errorf("FindByPath(%q) should not find anything", path)
+		}
+	}
+}
+
+func TestResolvePathMissing(t *testing.T) {
+	conf := testConfig(t)
+	photos := &Folder{ID: "photos", Name: "Photos"}
+	root := testTree(conf, &Folder{Folders: []*Folder{photos}})
+
+	got, missing := resolvePath(root, "/Photos/2023/Jan")
+	if got != photos || missing != "2023" {
+		t.Errorf("expected to get to /Photos and miss 2023, got %q and %q", got.fullPath(), missing)
+	}
+	if got, missing = resolvePath(root, "/Music/"); got != root || missing != "Music" {
+		t.Errorf("expected to stay at the root and miss Music, got %q and %q", got.fullPath(), missing)
+	}
+}
+
+func TestParseDelimitedListNoise(t *testing.T) {
+	logged := captureLog(t)
+	raw := gdriveList("\n",
+		row("id1", "a.txt", "regular", "12 B", "2024-01-02 03:04:05"),
+		"Found 1 files",
+	) + row("id2", "b.t")
+	rows, err := parseDelimitedList(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ID != "id1" {
+		t.Errorf("expected only the data row, got %+v", rows)
+	}
+	if len(*logged) != 2 {
+		t.Errorf("expected a warning for the summary and the partial line, got %q", *logged)
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		id   string
+		want []string
+	}{
+		{nil, "", []string{"gdrive", "files", "list", "--max", "500", "--field-separator", delim}},
+		{nil, "abc", []string{"gdrive", "files", "list", "--max", "500", "--field-separator", delim, "--parent", "abc"}},
+		{[]string{"-gdrive-json"}, "abc", []string{"gdrive", "files", "list", "--max", "500", "--json", "--parent", "abc"}},
+		{[]string{"-owned-only"}, "", []string{"gdrive", "files", "list", "--max", "500", "--field-separator", delim,
+			"--query", "'me' in owners and trashed = false"}},
+		{[]string{"-owned-only"}, `a'b\c`, []string{"gdrive", "files", "list", "--max", "500", "--field-separator", delim,
+			"--query", `'a\'b\\c' in parents and 'me' in owners and trashed = false`}},
+	}
+	for _, tt := range tests {
+		conf := testConfig(t, tt.args...)
+		got := (&Folder{ID: tt.id}).listCommand(conf)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("listCommand(%v, %q)\n got: %q\nwant: %q", tt.args, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestSizeFromString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"0", 0},
+		{"1234567", 1234567},
+		{"1,234,567", 1234567},
+		{"1,234,567 B", 1234567},
+		{"1.2e6", 1200000},
+		{"1.5E3 B", 1500},
+		{"1.5 KB", 1536},
+		{"1.5kb", 1536},
+		{"2 MB", 2 * 1024 * 1024},
+		{"1e0 GB", 1024 * 1024 * 1024},
+		{"1 KiB", 1024},
+	}
+	conf := &Config{}
+	for _, tt := range tests {
+		got, err := conf.sizeFromString(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("sizeFromString(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1.2.3 MB", "12 XB"} {
+		if _, err := conf.sizeFromString(in); err == nil {
+			t.Errorf("sizeFromString(%q) should fail", in)
+		}
+	}
+}
+
+func TestSizeFromStringSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"1.5 KB", 1500},
+		{"2mb", 2000000},
+		{"1 GB", 1000000000},
+		{"1 KiB", 1024},
+		{"1 MiB", 1024 * 1024},
+	}
+	conf := &Config{SI: true}
+	for _, tt := range tests {
+		got, err := conf.sizeFromString(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("sizeFromString(%q) with -si = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		si   bool
+		in   int64
+		want string
+	}{
+		{false, 0, ""},
+		{false, 1023, "1023b"},
+		{false, 1024, "1.0kb"},
+		{false, 1536, "1.5kb"},
+		{false, 1048524, "1023.9kb"},
+		{false, 1048575, "1.0mb"},
+		{false, 1 << 20, "1.0mb"},
+		{false, 1<<30 - 1, "1.0gb"},
+		{false, 1<<40 - 1, "1.0tb"},
+		{false, 3 << 40, "3.0tb"},
+		{true, 999, "999b"},
+		{true, 1000, "1.0kb"},
+		{true, 1024, "1.0kb"},
+		{true, 999949, "999.9kb"},
+		{true, 999999, "1.0mb"},
+		{true, 1500000, "1.5mb"},
+		{true, 999999999, "1.0gb"},
+		{true, 2e12, "2.0tb"},
+	}
+	for _, tt := range tests {
+		if got := (&Config{SI: tt.si}).formatSize(tt.in); got != tt.want {
+			t.Errorf("formatSize(%d) with si=%v = %q, want %q", tt.in, tt.si, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSizeRoundTrips(t *testing.T) {
+	for _, si := range []bool{false, true} {
+		conf := &Config{SI: si}
+		for _, s := range []string{"1.0kb", "1.5mb", "2.0gb", "1.0tb"} {
+			n, err := conf.sizeFromString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := conf.formatSize(int64(n)); got != s {
+				t.Errorf("formatSize(sizeFromString(%q)) with si=%v = %q", s, si, got)
+			}
+		}
+	}
+}
+
+func TestParseListInvalidSize(t *testing.T) {
+	logged := captureLog(t)
+	conf := testConfig(t)
+	f := &Folder{ID: "root"}
+	raw := gdriveList("\n",
+		row("id1", "a.txt", "regular", "lots", "2024-01-02 03:04:05"),
+		row("id2", "b.txt", "regular", "1,024 B", "2024-01-02 03:04:05"),
+	)
+	if err := f.parseList(conf, raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Files) != 1 || f.Files[0].ID != "id2" || f.Files[0].Size != 1024 {
+		t.Errorf("expected only the file with a valid size, got %+v", f.Files)
+	}
+	if len(*logged) != 1 || !strings.HasPrefix((*logged)[0], "warn ") {
+		t.Errorf("expected a warning about the skipped file, got %q", *logged)
+	}
+}
+
+func TestDeepScanDuration(t *testing.T) {
+	tests := []struct {
+		args  []string
+		calls int64
+	}{
+		// the listings of the root and a
+		{nil, 2},
+		// one info and one listing per root
+		{[]string{"-root-id", "r1", "-root-id", "r2"}, 4},
+	}
+	for _, tt := range tests {
+		conf := testConfig(t, tt.args...)
+		dir := fakeGdrive(t, map[string]string{
+			"root": gdriveList("\n", row("a", "a", "folder", "", "2024-01-02 03:04:05")),
+			"a":    gdriveList("\n"),
+			"r1":   gdriveList("\n"),
+			"r2":   gdriveList("\n"),
+		})
+		// the first calls are the slow ones: the info of the roots, or the listing of the root
+		fakeCommand(t, "gdrive", "[ \"$2\" = info ] && { sleep 0.2; printf 'Name: %s\\n' \"$3\"; exit; }\n"+
+			"case \"$*\" in *--parent*) ;; *) sleep 0.2;; esac\n"+
+			"exec '"+filepath.Join(dir, "gdrive")+"' \"$@\"\n")
+		root := testTree(conf, &Folder{ID: conf.RootID, virtual: len(conf.RootIDs) > 1})
+
+		var done progressEvent
+		prev := progress
+		progress = func(e progressEvent) {
+			if e.Event == "done" {
+				done = e
+			}
+		}
+		err := deepScan(conf, root)
+		progress = prev
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done.Calls != tt.calls || done.Seconds < 0.2 {
+			t.Errorf("%v: expected %d calls in at least 0.2s, got %d calls in %.2fs", tt.args, tt.calls, done.Calls, done.Seconds)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"photo.jpg", 0, "photo.jpg"},
+		{"photo.jpg", 20, "photo.jpg"},
+		{"photo.jpg", 9, "photo.jpg"},
+		{"photo.jpg", 5, "ph…pg"},
+		{"photo.jpg", 1, "…"},
+		{"日本語", 6, "日本語"},
+		{"日本語", 5, "日…語"},
+		// a double-width rune can't be cut in half, what's left over stays empty
+		{"日本語", 4, "…語"},
+		{"日本語", 2, "…"},
+		{"日本語", 1, "…"},
+		{"a日b", 3, "a…b"},
+		{"ab日本cd", 5, "ab…cd"},
+		{"日本語のファイル.txt", 7, "日…txt"},
+		{"日本語のファイル.txt", 8, "日….txt"},
+		{"日本語のファイル.txt", 9, "日本….txt"},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if tt.width > 0 && runewidth.StringWidth(got) > tt.width {
+			t.Errorf("truncate(%q, %d) = %q is wider than %d", tt.in, tt.width, got, tt.width)
+		}
+	}
+}
+
+func TestDeepScanProgressWithoutExcluded(t *testing.T) {
+	conf := testConfig(t, "-exclude-path", "/skip")
+	fakeGdrive(t, map[string]string{
+		"root": gdriveList("\n",
+			row("s", "skip", "folder", "", "2024-01-02 03:04:05"),
+			row("k", "keep", "folder", "", "2024-01-02 03:04:05"),
+		),
+		"k": gdriveList("\n"),
+	})
+	root := testTree(conf, &Folder{})
+	deep := &goDeep{max: 1, onUpdate: func(*Folder) {}}
+	if err := root.ensureData(conf, false, deep); err != nil {
+		t.Fatal(err)
+	}
+	if deep.cur != 2 || deep.max != 2 {
+		t.Errorf("expected 2 of 2 folders to be done, got %d of %d", deep.cur, deep.max)
+	}
+}
+
+func TestPathName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Photos", "Photos"},
+		{"a/b", "a_b"},
+		{"/", "_"},
+		{".", "_."},
+		{"..", "_.."},
+		{"...", "..."},
+		{".hidden", ".hidden"},
+		{"../etc", ".._etc"},
+	}
+	for _, tt := range tests {
+		if got := pathName(tt.in); got != tt.want {
+			t.Errorf("pathName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	conf := testConfig(t)
+	up := &Folder{ID: "up", Name: ".."}
+	slash := &Folder{ID: "slash", Name: "a/b", Folders: []*Folder{up}}
+	testTree(conf, &Folder{Folders: []*Folder{slash}})
+	if got := up.fullPath(); got != "/a_b/_.." {
+		t.Errorf("expected names to stay within their segment, got %q", got)
+	}
+	if got := slash.filePath(&File{Name: "../x"}); got != "/a_b/.._x" {
+		t.Errorf("expected file names to stay within their segment, got %q", got)
+	}
+}
+
+func TestDeepScanChangesTreeOnUIGoroutine(t *testing.T) {
+	conf := testConfig(t)
+	fakeGdrive(t, map[string]string{
+		"root": gdriveList("\n",
+			row("a", "a", "folder", "", "2024-01-02 03:04:05"),
+			row("b", "b", "folder", "", "2024-01-02 03:04:05"),
+		),
+		"a": gdriveList("\n", row("a1", "a1.txt", "regular", "10", "2024-01-02 03:04:05")),
+		"b": gdriveList("\n", row("b1", "b1.txt", "regular", "20", "2024-01-02 03:04:05")),
+	})
+	root := testTree(conf, &Folder{})
+
+	// a stand-in for the TUI, which keeps drawing and changing the tree on its own goroutine
+	updates := make(chan func())
+	prev := updateTree
+	updateTree = func(fn func()) {
+		done := make(chan struct{})
+		updates <- func() {
+			fn()
+			close(done)
+		}
+		<-done
+	}
+	t.Cleanup(func() { updateTree = prev })
+
+	scanned := make(chan error)
+	go func() { scanned <- deepScan(conf, root) }()
+	for {
+		select {
+		case fn := <-updates:
+			fn()
+		case err := <-scanned:
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root.size != 30 || root.files != 2 {
+				t.Errorf("expected both files to be counted, got size %d and %d files", root.size, root.files)
+			}
+			return
+		default:
+			root.rebuild(conf)
+			root.explorer(conf, tview.NewList(), view{sortBy: "size", root: root, top: root}, false, nil)
+		}
+	}
+}
+
+// aggregates are all that rebuild computes for a folder and its parents
+type aggregates struct {
+	size, directSize, estimated                           int64
+	files, folders, known, unknown, unscanned, staleTotal int
+}
+
+func aggregatesOf(f *Folder) aggregates {
+	return aggregates{f.size, f.directSize, f.estimated, f.files, f.folders, f.known, f.unknown, f.unscanned, f.staleTotal}
+}
+
+// checkAggregates compares what incremental updates left in the tree with a rebuild from scratch
+func checkAggregates(t *testing.T, conf *Config, root *Folder) {
+	t.Helper()
+	all := []*Folder{root}
+	got := []aggregates{}
+	for i := 0; i < len(all); i++ {
+		all = append(all, all[i].Folders...)
+		got = append(got, aggregatesOf(all[i]))
+	}
+	root.rebuild(conf)
+	for i := range all {
+		if want := aggregatesOf(all[i]); got[i] != want {
+			t.Errorf("aggregates of %s are off:\n got: %+v\nwant: %+v", all[i].fullPath(), got[i], want)
+		}
+	}
+}
+
+func TestDeepScanFreshFolderWithUnscanned(t *testing.T) {
+	conf := testConfig(t)
+	fakeGdrive(t, map[string]string{
+		"r": gdriveList("\n", row("r1", "r1.txt", "regular", "7", "2024-01-02 03:04:05")),
+	})
+	now := time.Now().Unix()
+	r := &Folder{ID: "r", Name: "R"}
+	a := &Folder{ID: "a", Name: "A", LastUpdate: now, Folders: []*Folder{r}, Files: []*File{{ID: "a1", Name: "a1.txt", Size: 100}}}
+	root := testTree(conf, &Folder{LastUpdate: now, Folders: []*Folder{a}})
+
+	if err := deepScan(conf, a); err != nil {
+		t.Fatal(err)
+	}
+	if root.size != 107 || root.files != 2 || root.unscanned != 0 {
+		t.Errorf("expected 107 bytes in 2 files and nothing unscanned, got %d bytes in %d files and %d unscanned",
+			root.size, root.files, root.unscanned)
+	}
+	checkAggregates(t, conf, root)
+}
+
+func TestDeepScanResumes(t *testing.T) {
+	conf := testConfig(t)
+	dir := fakeGdrive(t, map[string]string{
+		"root": gdriveList("\n",
+			row("a", "a", "folder", "", "2024-01-02 03:04:05"),
+			row("b", "b", "folder", "", "2024-01-02 03:04:05"),
+		),
+		"a": gdriveList("\n", row("a1", "a1.txt", "regular", "10", "2024-01-02 03:04:05")),
+	})
+	root := testTree(conf, &Folder{})
+
+	// b can't be listed, which interrupts the scan after a
+	if err := deepScan(conf, root); err == nil {
+		t.Fatal("expected the scan to fail at b")
+	}
+	if root.size != 10 || root.unscanned != 1 {
+		t.Errorf("expected a to be kept and b to be unscanned, got %d bytes and %d unscanned", root.size, root.unscanned)
+	}
+	checkAggregates(t, conf, root)
+
+	raw := gdriveList("\n", row("b1", "b1.txt", "regular", "20", "2024-01-02 03:04:05"))
+	if err := os.WriteFile(filepath.Join(dir, "b.list"), []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deep := &goDeep{max: 1, onUpdate: func(*Folder) {}}
+	if err := root.ensureData(conf, false, deep); err != nil {
+		t.Fatal(err)
+	}
+	if deep.fetched != 1 {
+		t.Errorf("expected the resumed scan to only fetch b, it fetched %d folders", deep.fetched)
+	}
+	if root.size != 30 || root.unscanned != 0 {
+		t.Errorf("expected 30 bytes and nothing unscanned, got %d bytes and %d unscanned", root.size, root.unscanned)
+	}
+	checkAggregates(t, conf, root)
+}
+
+func TestCacheVersion(t *testing.T) {
+	conf := testConfig(t)
+	root := &Folder{ID: "root", dirty: true}
+	if err := save(conf, root); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := load(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version != cacheVersion {
+		t.Errorf("expected the cache to be saved with version %d, got %d", cacheVersion, loaded.Version)
+	}
+
+	// from before versioning
+	if err := os.WriteFile(conf.SavePath, []byte(`{"ID":"root"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(conf); err != nil {
+		t.Errorf("expected a cache without a version to load, got %v", err)
+	}
+
+	newer := fmt.Sprintf(`{"ID":"root","Version":%d}`, cacheVersion+1)
+	if err := os.WriteFile(conf.SavePath, []byte(newer), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(conf); err == nil || !strings.Contains(err.Error(), "please update") {
+		t.Errorf("expected a newer cache to be refused, got %v", err)
+	}
+
+	var out strings.Builder
+	if err := describeCache(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), fmt.Sprintf("schema version %d", cacheVersion)) {
+		t.Errorf("expected the description to name the schema version, got:\n%s", out.String())
+	}
+}
+
+func TestSumDateRange(t *testing.T) {
+	conf := testConfig(t, "-newer-than", "30d")
+	recent := time.Now().AddDate(0, 0, -1).Unix()
+	old := time.Now().AddDate(-1, 0, 0).Unix()
+	archive := &Folder{ID: "archive", Name: "archive", Files: []*File{{ID: "a", Name: "a.txt", Size: 100, Date: old}}}
+	root := testTree(conf, &Folder{Folders: []*Folder{archive}, Files: []*File{
+		{ID: "new", Name: "new.txt", Size: 10, Date: recent},
+		{ID: "old", Name: "old.txt", Size: 20, Date: old},
+		{ID: "undated", Name: "undated.txt", Size: 40},
+	}})
+
+	if root.size != 10 || root.files != 1 || root.folders != 1 {
+		t.Errorf("expected only new.txt to count, got %d bytes in %d files and %d folders", root.size, root.files, root.folders)
+	}
+	// a folder without any file in range is still there, it's just empty
+	if archive.size != 0 || archive.files != 0 {
+		t.Errorf("expected the archive to be empty, got %d bytes in %d files", archive.size, archive.files)
+	}
+
+	got := []string{}
+	for _, row := range exportRows(conf, root) {
+		got = append(got, fmt.Sprintf("%s %s %d", row.Type, row.Path, row.Size))
+	}
+	want := []string{"folder / 10", "file /new.txt 10", "folder /archive 0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected export\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestProgressbarStyles(t *testing.T) {
+	tests := []struct {
+		style string
+		want  []string // at 0, 0.375, 0.55 and 1 with a width of 4
+	}{
+		{"blocks", []string{"    ", "█▌  ", "██▎ ", "████"}},
+		{"ascii", []string{"----", "##--", "##--", "####"}},
+		{"dots", []string{"    ", "⣿⡇  ", "⣿⣿⡄ ", "⣿⣿⣿⣿"}},
+		{"arrows", []string{"    ", "»›  ", "»»  ", "»»»»"}},
+	}
+	for _, tt := range tests {
+		for i, progress := range []float64{0, 0.375, 0.55, 1} {
+			if got := progressbar(progress, 4, barStyles[tt.style]); got != tt.want[i] {
+				t.Errorf("%s at %v = %q, want %q", tt.style, progress, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestIncrementalUpdatesMatchRebuild(t *testing.T) {
+	conf := testConfig(t, "-estimate-doc-size")
+	fakeGdrive(t, map[string]string{
+		"b": gdriveList("\n",
+			row("b1", "b1.txt", "regular", "20", "2024-01-02 03:04:05"),
+			row("c", "c", "folder", "", "2024-01-02 03:04:05"),
+			row("d", "d", "folder", "", "2024-01-02 03:04:05"),
+		),
+	})
+	old := time.Now().Add(-48 * time.Hour).Unix()
+	now := time.Now().Unix()
+	c := &Folder{ID: "c", Name: "c", LastUpdate: old, Files: []*File{{ID: "c1", Name: "c1.txt", Size: 5}}}
+	b := &Folder{ID: "b", Name: "b", LastUpdate: old, Folders: []*Folder{c}, Files: []*File{{ID: "b0", Name: "gone.txt", Size: 1}}}
+	a := &Folder{ID: "a", Name: "a", LastUpdate: now, Folders: []*Folder{b, {ID: "e", Name: "e"}}}
+	root := testTree(conf, &Folder{LastUpdate: now, Folders: []*Folder{a}})
+
+	// like r, only the folder itself is fetched
+	if err := b.ensureData(conf, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if root.size != 25 || root.unscanned != 2 {
+		t.Errorf("expected 25 bytes with d and e unscanned, got %d bytes and %d unscanned", root.size, root.unscanned)
+	}
+	checkAggregates(t, conf, root)
+
+	// like -auto-refresh, which fetches in the background and applies the listing later
+	raw := gdriveList("\n",
+		row("b1", "b1.txt", "regular", "20", "2024-01-02 03:04:05"),
+		row("b2", "b2", "document", "", "2024-01-02 03:04:05"),
+		row("c", "c", "folder", "", "2024-01-02 03:04:05"),
+	)
+	if err := b.applyList(conf, raw); err != nil {
+		t.Fatal(err)
+	}
+	checkAggregates(t, conf, root)
+}
+
+func TestStaleBadgeAfterRefresh(t *testing.T) {
+	fakeGdrive(t, map[string]string{
+		"b": gdriveList("\n", row("d", "d", "folder", "", "2024-01-02 03:04:05")),
+	})
+	old := time.Now().Add(-48 * time.Hour).Unix()
+	now := time.Now().Unix()
+	d := &Folder{ID: "d", Name: "d", LastUpdate: old}
+	b := &Folder{ID: "b", Name: "b", LastUpdate: old, Folders: []*Folder{d}}
+	a := &Folder{ID: "a", Name: "a", LastUpdate: now, Folders: []*Folder{b, {ID: "c", Name: "c"}}}
+	root := testTree(testConfig(t), &Folder{LastUpdate: now, Folders: []*Folder{a}})
+
+	badges := func(want map[string][2]int) {
+		t.Helper()
+		for _, f := range []*Folder{root, a, b} {
+			direct := f.staleCount(testConfig(t, "-stale-badge", "direct"))
+			total := f.staleCount(testConfig(t, "-stale-badge", "total"))
+			if w := want[f.fullPath()]; direct != w[0] || total != w[1] {
+				t.Errorf("badges of %s are %d direct and %d total, want %d and %d", f.fullPath(), direct, total, w[0], w[1])
+			}
+		}
+	}
+	// b, c (never scanned) and d are stale
+	badges(map[string][2]int{"/": {0, 3}, "/a": {2, 3}, "/a/b": {1, 1}})
+
+	conf := testConfig(t, "-stale-badge", "total")
+	if err := b.ensureData(conf, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	// only b was refreshed, its d is still stale
+	badges(map[string][2]int{"/": {0, 2}, "/a": {1, 2}, "/a/b": {1, 1}})
+	checkAggregates(t, conf, root)
+}
+
+// BenchmarkLoad loads a synthetic cache of 200 folders with 500 files each (~7MB)
+func BenchmarkLoad(b *testing.B) {
+	conf := testConfig(b)
+	root := &Folder{ID: "root", Name: "root", LastUpdate: time.Now().Unix()}
+	for i := 0; i < 200; i++ {
+		folder := &Folder{ID: "folder" + strconv.Itoa(i), Name: "folder " + strconv.Itoa(i), LastUpdate: time.Now().Unix()}
+		for j := 0; j < 500; j++ {
+			folder.Files = append(folder.Files, &File{
+				ID:   "file" + strconv.Itoa(i) + "-" + strconv.Itoa(j),
+				Name: "IMG_" + strconv.Itoa(j) + ".jpg",
+				Size: 1000 + j,
+				Date: 1700000000 + int64(j),
+			})
+		}
+		root.Folders = append(root.Folders, folder)
+	}
+	root.dirty = true
+	if err := save(conf, root); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := load(conf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
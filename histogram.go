@@ -0,0 +1,79 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+type histogramBucket struct {
+	label string
+	max   int64 // exclusive upper bound, 0 for the last bucket
+	count int
+	size  int64
+}
+
+// sizeHistogram sorts all files into buckets by size, in the same units that -si picks for showing sizes
+func sizeHistogram(conf *Config, root *Folder) []histogramBucket {
+	kb := int64(conf.unitBase())
+	res := []histogramBucket{
+		{label: "< 1kb", max: kb},
+		{label: "1-10kb", max: 10 * kb},
+		{label: "10-100kb", max: 100 * kb},
+		{label: "100kb-1mb", max: kb * kb},
+		{label: "1-10mb", max: 10 * kb * kb},
+		{label: "10-100mb", max: 100 * kb * kb},
+		{label: "100mb-1gb", max: kb * kb * kb},
+		{label: "> 1gb"},
+	}
+
+	root.walkFiles(conf, func(path string, file *File) {
+		size := int64(file.Size)
+		i := 0
+		for ; i < len(res)-1; i++ {
+			if size < res[i].max {
+				break
+			}
+		}
+		res[i].count += 1
+		res[i].size += size
+	})
+	return res
+}
+
+func newHistogramView(conf *Config, root *Folder) *tview.TextView {
+	buckets := sizeHistogram(conf, root)
+
+	var maxCount int
+	var maxSize int64
+	for i := range buckets {
+		maxCount = max(maxCount, buckets[i].count)
+		maxSize = max(maxSize, buckets[i].size)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-10s %8s %-20s %8s %-20s\n", "", "files", "", "size", "")
+	for i := range buckets {
+		b := buckets[i]
+		var countPct, sizePct float64
+		if maxCount > 0 {
+			countPct = float64(b.count) / float64(maxCount)
+		}
+		if maxSize > 0 {
+			sizePct = float64(b.size) / float64(maxSize)
+		}
+		fmt.Fprintf(&sb, "%-10s %8d %s %8s %s\n",
+			b.label,
+			b.count, progressbar(countPct, 20, conf.barRunes()),
+			conf.formatSize(b.size), progressbar(sizePct, 20, conf.barRunes()),
+		)
+	}
+
+	view := tview.NewTextView().SetText(sb.String())
+	view.SetBorder(true).SetTitle(" file sizes across " + root.fullPath() + " ")
+	return view
+}
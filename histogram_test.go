@@ -0,0 +1,31 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import "testing"
+
+func TestSizeHistogramUnits(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []int // files per bucket
+	}{
+		{nil, []int{2, 1, 0, 1, 0, 0, 0, 0}},
+		{[]string{"-si"}, []int{1, 2, 0, 0, 1, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		conf := testConfig(t, tt.args...)
+		root := testTree(conf, &Folder{Files: []*File{
+			{Name: "a", Size: 999},
+			{Name: "b", Size: 1000},
+			{Name: "c", Size: 1024},
+			{Name: "d", Size: 1000000},
+		}})
+		buckets := sizeHistogram(conf, root)
+		for i, b := range buckets {
+			if b.count != tt.want[i] {
+				t.Errorf("%v: bucket %s has %d files, want %d", tt.args, b.label, b.count, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreList holds glob patterns (like .gitignore) compiled to regular expressions.
+//
+// Patterns without a slash match the name of an entry anywhere in the tree,
+// patterns with a slash match its full path. A trailing slash restricts the
+// pattern to folders. `*`, `?` and classes like `[a-z]` or `[!0-9]` don't cross
+// path separators, `**` does.
+type ignoreList []ignorePattern
+
+type ignorePattern struct {
+	re         *regexp.Regexp
+	folderOnly bool
+}
+
+func loadIgnoreFile(path string) (ignoreList, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := ignoreList{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := compileIgnorePattern(line)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func compileIgnorePattern(glob string) (ignorePattern, error) {
+	res := ignorePattern{}
+	if strings.HasSuffix(glob, "/") {
+		res.folderOnly = true
+		glob = strings.TrimSuffix(glob, "/")
+	}
+
+	var expr strings.Builder
+	if strings.Contains(glob, "/") {
+		expr.WriteString("^/?")
+	} else {
+		expr.WriteString("(^|/)")
+	}
+
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				expr.WriteString(".*")
+				i++
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			class, n := globClass(runes[i+1:])
+			if n == 0 {
+				expr.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			expr.WriteString(class)
+			i += n
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return res, err
+	}
+	res.re = re
+	return res, nil
+}
+
+// globClass translates a class like [a-z] or [!abc] into a regular expression, given what
+// follows the opening bracket. It returns how many runes it used, 0 if the class isn't closed.
+func globClass(runes []rune) (string, int) {
+	var res strings.Builder
+	res.WriteString("[")
+	i := 0
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		res.WriteString("^/")
+		i++
+	}
+	for start := i; i < len(runes); i++ {
+		r := runes[i]
+		// a ] right at the start is part of the class, like in gitignore
+		if r == ']' && i > start {
+			res.WriteString("]")
+			return res.String(), i + 1
+		}
+		if r == '/' {
+			return "", 0
+		}
+		if r == '\\' || r == '[' || r == ']' || r == '^' {
+			res.WriteRune('\\')
+		}
+		res.WriteRune(r)
+	}
+	return "", 0
+}
+
+// exactPathPattern matches exactly one folder by its full path, like /Photos/Raw
+func exactPathPattern(path string) (ignorePattern, error) {
+	path = filepath.Clean("/" + path)
+	if path == "/" {
+		return ignorePattern{}, errors.New("the root can't be excluded")
+	}
+	re, err := regexp.Compile("^" + regexp.QuoteMeta(path) + "$")
+	return ignorePattern{re: re, folderOnly: true}, err
+}
+
+// matches reports if the entry with the given full path is ignored
+func (l ignoreList) matches(path string, isFolder bool) bool {
+	for i := range l {
+		p := l[i]
+		if p.folderOnly && !isFolder {
+			continue
+		}
+		if p.re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import "testing"
+
+func TestIgnorePatterns(t *testing.T) {
+	tests := []struct {
+		glob     string
+		path     string
+		isFolder bool
+		want     bool
+	}{
+		// without a slash, the name matches anywhere
+		{"*.tmp", "/a.tmp", false, true},
+		{"*.tmp", "/x/y/a.tmp", false, true},
+		{"*.tmp", "/a.tmp.txt", false, false},
+		{"node_modules", "/src/node_modules", true, true},
+		{"node_modules", "/src/node_modules_old", true, false},
+		// * and ? stay within one name
+		{"a*", "/ab/c", true, false},
+		{"a?c", "/abc", false, true},
+		{"a?c", "/a/c", false, false},
+		{"a?c", "/abbc", false, false},
+		// with a slash, the full path has to match
+		{"/Photos/Raw", "/Photos/Raw", true, true},
+		{"Photos/Raw", "/Photos/Raw", true, true},
+		{"Photos/Raw", "/Backup/Photos/Raw", true, false},
+		{"Photos/*", "/Photos/2024", true, true},
+		{"Photos/*", "/Photos/2024/Jan", true, false},
+		// ** crosses folders
+		{"Photos/**", "/Photos/2024/Jan", true, true},
+		{"**/cache", "/a/b/cache", true, true},
+		// a trailing slash only matches folders
+		{"build/", "/src/build", true, true},
+		{"build/", "/src/build", false, false},
+		{"build", "/src/build", false, true},
+		// classes
+		{"IMG_[0-9]*", "/IMG_1234.jpg", false, true},
+		{"IMG_[0-9]*", "/IMG_x.jpg", false, false},
+		{"[!.]*", "/visible", false, true},
+		{"[!.]*", "/.hidden", false, false},
+		{"[]a]", "/]", false, true},
+		{"a[^]b", "/a^b", false, false},
+		{"a[/]b", "/a[/]b", false, true},
+		{"a[b", "/a[b", false, true},
+		// everything else is literal
+		{"a+b (1).txt", "/a+b (1).txt", false, true},
+		{"a.b", "/axb", false, false},
+	}
+	for _, tt := range tests {
+		p, err := compileIgnorePattern(tt.glob)
+		if err != nil {
+			t.Errorf("compileIgnorePattern(%q): %v", tt.glob, err)
+			continue
+		}
+		if got := (ignoreList{p}).matches(tt.path, tt.isFolder); got != tt.want {
+			t.Errorf("%q matches %s (folder: %v) = %v, want %v", tt.glob, tt.path, tt.isFolder, got, tt.want)
+		}
+	}
+}
+
+func TestExactPathPattern(t *testing.T) {
+	p, err := exactPathPattern("Photos/Raw/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := ignoreList{p}
+	if !l.matches("/Photos/Raw", true) || l.matches("/Photos/Raw/2024", true) || l.matches("/Photos/Raw", false) {
+		t.Errorf("expected only the folder /Photos/Raw to match")
+	}
+	if _, err := exactPathPattern("/"); err == nil {
+		t.Errorf("the root can't be excluded")
+	}
+}
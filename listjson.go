@@ -0,0 +1,76 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// gdriveJSONFile is one file of gdrive files list --json, named like in the Drive API
+type gdriveJSONFile struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	MimeType    string      `json:"mimeType"`
+	Size        json.Number `json:"size"`
+	CreatedTime time.Time   `json:"createdTime"`
+	Owners      []struct {
+		EmailAddress string `json:"emailAddress"`
+	} `json:"owners"`
+}
+
+// parseJSONList reads the rows of gdrive files list --json. It is either a list of
+// files, or an object with them in "files" like the Drive API returns them.
+func parseJSONList(raw string) ([]listRow, error) {
+	var files []gdriveJSONFile
+	if strings.HasPrefix(strings.TrimSpace(raw), "{") {
+		var page struct {
+			Files []gdriveJSONFile `json:"files"`
+		}
+		if err := json.Unmarshal([]byte(raw), &page); err != nil {
+			return nil, errors.New("Unexpected format of gdrive list --json: " + err.Error())
+		}
+		files = page.Files
+	} else if err := json.Unmarshal([]byte(raw), &files); err != nil {
+		return nil, errors.New("Unexpected format of gdrive list --json: " + err.Error())
+	}
+
+	rows := make([]listRow, 0, len(files))
+	for _, file := range files {
+		row := listRow{
+			ID:       file.ID,
+			Name:     file.Name,
+			Type:     gdriveType(file.MimeType),
+			Size:     file.Size.String(),
+			MimeType: file.MimeType,
+		}
+		if !file.CreatedTime.IsZero() {
+			row.Date = file.CreatedTime.Unix()
+		}
+		if row.Type == "regular" && row.Size == "" {
+			row.Size = "0"
+		}
+		if len(file.Owners) > 0 {
+			row.Owner = file.Owners[0].EmailAddress
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// gdriveType is the type gdrive's delimited listing shows for a MIME type
+func gdriveType(mime string) string {
+	switch mime {
+	case folderMime:
+		return "folder"
+	case "application/vnd.google-apps.shortcut":
+		return "shortcut"
+	}
+	if strings.HasPrefix(mime, "application/vnd.google-apps.") {
+		return "document"
+	}
+	return "regular"
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseJSONList(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Unix()
+	file := `{"id": "f", "name": "a.txt", "mimeType": "text/plain", "size": "12", "createdTime": "2024-01-02T03:04:05Z",
+		"owners": [{"emailAddress": "me@example.com"}, {"emailAddress": "you@example.com"}]}`
+	fileRow := listRow{ID: "f", Name: "a.txt", Type: "regular", Size: "12", Date: created, MimeType: "text/plain", Owner: "me@example.com"}
+
+	tests := []struct {
+		name string
+		raw  string
+		want []listRow
+	}{
+		{"array", "[" + file + "]", []listRow{fileRow}},
+		{"files object", `{"files": [` + file + `], "nextPageToken": ""}`, []listRow{fileRow}},
+		{"surrounded by whitespace", "\n  {\"files\": [" + file + "]}\n", []listRow{fileRow}},
+		{"empty", "[]", []listRow{}},
+		{"folder without size",
+			`[{"id": "d", "name": "sub", "mimeType": "application/vnd.google-apps.folder", "createdTime": "2024-01-02T03:04:05Z"}]`,
+			[]listRow{{ID: "d", Name: "sub", Type: "folder", Date: created, MimeType: folderMime}}},
+		{"document",
+			`[{"id": "g", "name": "doc", "mimeType": "application/vnd.google-apps.document", "size": "1024"}]`,
+			[]listRow{{ID: "g", Name: "doc", Type: "document", Size: "1024", MimeType: "application/vnd.google-apps.document"}}},
+		{"shortcut",
+			`[{"id": "s", "name": "link", "mimeType": "application/vnd.google-apps.shortcut"}]`,
+			[]listRow{{ID: "s", Name: "link", Type: "shortcut", MimeType: "application/vnd.google-apps.shortcut"}}},
+		{"file without size or createdTime",
+			`[{"id": "e", "name": "empty", "mimeType": "application/octet-stream"}]`,
+			[]listRow{{ID: "e", Name: "empty", Type: "regular", Size: "0", MimeType: "application/octet-stream"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseJSONList(tt.raw)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s:\n got: %+v\nwant: %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseJSONListMalformed(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"Found 3 files",
+		`[{"id": "f", "name": "a.txt"`,
+		`{"files": {"id": "f"}}`,
+		`[{"id": "f", "size": "twelve"}]`,
+		`[{"id": "f", "createdTime": "yesterday"}]`,
+	} {
+		if rows, err := parseJSONList(raw); err == nil {
+			t.Errorf("parseJSONList(%q) should fail, got %+v", raw, rows)
+		}
+	}
+}
+
+func TestGdriveType(t *testing.T) {
+	tests := []struct {
+		mime string
+		want string
+	}{
+		{folderMime, "folder"},
+		{"application/vnd.google-apps.shortcut", "shortcut"},
+		{"application/vnd.google-apps.document", "document"},
+		{"application/vnd.google-apps.spreadsheet", "document"},
+		{"application/vnd.google-apps.presentation", "document"},
+		{"application/pdf", "regular"},
+		{"", "regular"},
+	}
+	for _, tt := range tests {
+		if got := gdriveType(tt.mime); got != tt.want {
+			t.Errorf("gdriveType(%q) = %q, want %q", tt.mime, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+func (l LOG_LEVEL) String() string {
+	if int(l) < len(logLevelNames) {
+		return logLevelNames[l]
+	}
+	return "unknown"
+}
+
+func parseLogLevel(s string) (LOG_LEVEL, error) {
+	for i := range logLevelNames {
+		if logLevelNames[i] == strings.ToLower(s) {
+			return LOG_LEVEL(i), nil
+		}
+	}
+	return 0, errors.New("unsupported log level: " + s + " (use " + strings.Join(logLevelNames, ", ") + ")")
+}
+
+func formatLog(msg string, level LOG_LEVEL) string {
+	return time.Now().Format("15:04:05") + " " + strings.ToUpper(level.String()) + " " + msg
+}
+
+// newWriterLog logs everything at or above minLevel to w, which is stderr outside of the TUI
+func newWriterLog(w io.Writer, minLevel LOG_LEVEL) func(string, LOG_LEVEL) {
+	return func(msg string, level LOG_LEVEL) {
+		if level < minLevel {
+			return
+		}
+		fmt.Fprintln(w, formatLog(msg, level))
+	}
+}
+
+func stderrLog(minLevel LOG_LEVEL) func(string, LOG_LEVEL) {
+	return newWriterLog(os.Stderr, minLevel)
+}
+
+// logBuffer keeps the most recent log lines while the TUI owns the screen
+type logBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	max   int
+}
+
+func (b *logBuffer) add(msg string, level LOG_LEVEL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, formatLog(msg, level))
+	if len(b.lines) > b.max {
+		b.lines = b.lines[len(b.lines)-b.max:]
+	}
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Join(b.lines, "\n")
+}
+
+// statusLine shows the most recent messages below the explorer. They are logged from
+// background scans too, so the view is only updated through queue, on the UI's goroutine.
+type statusLine struct {
+	mu     sync.Mutex
+	lines  []string
+	max    int
+	queued bool // an update of the view is on its way
+	view   *tview.TextView
+	queue  func(func())
+}
+
+func (s *statusLine) add(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, msg)
+	if len(s.lines) > s.max {
+		s.lines = s.lines[len(s.lines)-s.max:]
+	}
+	if s.queued {
+		return
+	}
+	s.queued = true
+	// queueing waits while the UI is busy, or before it even runs, nobody who logs should have to
+	go s.queue(func() {
+		s.mu.Lock()
+		text := strings.Join(s.lines, "\n")
+		s.queued = false
+		s.mu.Unlock()
+		s.view.SetText(text)
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// redirect points os.Stdout or os.Stderr to a file until the test ends and returns what was written to it
+func redirect(t *testing.T, std **os.File) func() string {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := *std
+	*std = file
+	t.Cleanup(func() {
+		*std = prev
+		file.Close()
+	})
+	return func() string {
+		raw, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+}
+
+func TestQuietOnlyLogsErrors(t *testing.T) {
+	conf, err := parseTestConfig(t, "-quiet", "-log-level", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.LogLevel != ERROR {
+		t.Fatalf("-quiet should log errors only, got log level %s", conf.LogLevel)
+	}
+
+	stdout := redirect(t, &os.Stdout)
+	stderr := redirect(t, &os.Stderr)
+	quiet := stderrLog(conf.LogLevel)
+	quiet("some debug", DEBUG)
+	quiet("some info", INFO)
+	quiet("some warning", WARN)
+	quiet("some error", ERROR)
+
+	if out := stdout(); out != "" {
+		t.Errorf("nothing should be logged to stdout, got %q", out)
+	}
+	lines := strings.Split(strings.TrimSpace(stderr()), "\n")
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], " ERROR some error") {
+		t.Errorf("only the error should be logged to stderr, got %q", lines)
+	}
+}
+
+func TestStatusLineConcurrent(t *testing.T) {
+	// like the UI, one goroutine runs all updates of views
+	updates := make(chan func(), 100)
+	done := make(chan struct{})
+	go func() {
+		for fn := range updates {
+			fn()
+		}
+		close(done)
+	}()
+	s := &statusLine{max: 3, view: tview.NewTextView(), queue: func(fn func()) { updates <- fn }}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				s.add(fmt.Sprintf("message %d from %d", j, i))
+			}
+		}()
+	}
+	wg.Wait()
+	for queued := true; queued; {
+		time.Sleep(time.Millisecond)
+		s.mu.Lock()
+		queued = s.queued
+		s.mu.Unlock()
+	}
+	close(updates)
+	<-done
+
+	want := strings.Join(s.lines, "\n")
+	if got := s.view.GetText(false); got != want || len(s.lines) != 3 {
+		t.Errorf("expected the view to show the last 3 messages %q, got %q", want, got)
+	}
+}
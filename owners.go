@@ -0,0 +1,72 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+type ownerTotal struct {
+	owner string // empty if the backend didn't report one
+	count int
+	size  int64
+}
+
+// ownerTotals sums up file sizes per owner across the whole tree, largest first
+func ownerTotals(conf *Config, root *Folder) []ownerTotal {
+	byOwner := map[string]*ownerTotal{}
+	root.walkFiles(conf, func(path string, file *File) {
+		t, ok := byOwner[file.Owner]
+		if !ok {
+			t = &ownerTotal{owner: file.Owner}
+			byOwner[file.Owner] = t
+		}
+		t.count += 1
+		t.size += int64(file.Size)
+	})
+
+	res := make([]ownerTotal, 0, len(byOwner))
+	for _, t := range byOwner {
+		res = append(res, *t)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return less("size", res[i].owner, res[j].owner, res[i].size, res[j].size, 0, 0)
+	})
+	return res
+}
+
+func newOwnersView(conf *Config, root *Folder) *tview.TextView {
+	totals := ownerTotals(conf, root)
+
+	var all int64
+	for i := range totals {
+		all += totals[i].size
+	}
+
+	var sb strings.Builder
+	for i := range totals {
+		t := totals[i]
+		var pct float64
+		if all > 0 {
+			pct = float64(t.size) / float64(all)
+		}
+		owner := t.owner
+		if owner == "" {
+			owner = "(unknown)"
+		}
+		fmt.Fprintf(&sb, "%9s %s %5.1f%% %8d files  %s\n",
+			conf.formatSize(t.size), progressbar(pct, 20, conf.barRunes()), pct*100, t.count, tview.Escape(owner))
+	}
+	if len(totals) == 1 && totals[0].owner == "" {
+		sb.WriteString("\nNo owners known, gdrive's file list doesn't report them.\n")
+	}
+
+	view := tview.NewTextView().SetText(sb.String())
+	view.SetBorder(true).SetTitle(" size by owner across " + root.fullPath() + " ")
+	return view
+}
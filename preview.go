@@ -0,0 +1,129 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	// text previews only show the start of a file
+	previewTextBytes = 4 * 1024
+	// image headers with the dimensions are at the start too, but may come after some metadata
+	previewImageBytes = 64 * 1024
+	// larger text files are rarely worth a look, they are logs or data dumps
+	previewMaxTextSize = 10 * 1024 * 1024
+)
+
+var previewTextExts = []string{".txt", ".md", ".csv", ".json", ".xml", ".yaml", ".yml", ".log", ".ini", ".conf", ".html", ".css", ".js", ".go", ".py", ".sh"}
+var previewImageExts = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// previewKind is text or image if the file can be previewed, empty if not
+func previewKind(file *File) string {
+	if file.Type != "" {
+		return ""
+	}
+	ext := strings.ToLower(file.Ext)
+	if slices.Contains(previewImageExts, ext) || strings.HasPrefix(file.MimeType, "image/") {
+		return "image"
+	}
+	if (slices.Contains(previewTextExts, ext) || strings.HasPrefix(file.MimeType, "text/")) && file.Size <= previewMaxTextSize {
+		return "text"
+	}
+	return ""
+}
+
+// previewBytes downloads at most maxBytes from the start of a file. gdrive streams the
+// whole file, so it is stopped once there is enough. If it ends before that, it either
+// sent the whole file or failed, and then what it printed to stderr is the error.
+func previewBytes(conf *Config, id string, maxBytes int) ([]byte, error) {
+	if err := conf.gdriveOnly("previewing"); err != nil {
+		return nil, err
+	}
+	parts := conf.gdrive("files", "download", "--stdout", id)
+	log("sh> "+strings.Join(parts, " "), DEBUG)
+	shCalls.Add(1)
+	cmd := exec.Command(parts[0], parts[1:]...)
+	// children of a killed command may still hold on to its output, don't wait for them
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.New("failed to start command: " + err.Error())
+	}
+	res, err := io.ReadAll(io.LimitReader(out, int64(maxBytes)))
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	if len(res) == maxBytes {
+		// we have enough, killing it is what makes it fail now
+		cmd.Process.Kill()
+		cmd.Wait()
+		return res, nil
+	}
+	if err := cmd.Wait(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("failed to download " + id + " for the preview: " + msg)
+	}
+	return res, nil
+}
+
+// preview describes the start of a text file, or the dimensions of an image
+func preview(conf *Config, file *File) (string, error) {
+	switch previewKind(file) {
+	case "text":
+		raw, err := previewBytes(conf, file.ID, previewTextBytes)
+		if err != nil {
+			return "", err
+		}
+		// a multi-byte character may be cut off at the end, that doesn't make it binary
+		for i := 0; i < utf8.UTFMax-1 && len(raw) > 0 && !utf8.Valid(raw); i++ {
+			raw = raw[:len(raw)-1]
+		}
+		if bytes.IndexByte(raw, 0) >= 0 || !utf8.Valid(raw) {
+			return "", errors.New(file.Name + " doesn't look like text")
+		}
+		return string(raw), nil
+	case "image":
+		raw, err := previewBytes(conf, file.ID, previewImageBytes)
+		if err != nil {
+			return "", err
+		}
+		img, format, err := image.DecodeConfig(bytes.NewReader(raw))
+		if err != nil {
+			return "", errors.New("can't read the dimensions of " + file.Name + ": " + err.Error())
+		}
+		return fmt.Sprintf("%s image, %d x %d pixels", format, img.Width, img.Height), nil
+	}
+	return "", errors.New("no preview for " + file.Name + ", only small text files and images have one")
+}
+
+func newPreviewView(conf *Config, file *File, text string) *tview.TextView {
+	view := tview.NewTextView().SetText(text)
+	view.SetBorder(true).SetTitle(" " + tview.Escape(file.Name) + " (" + conf.formatSize(int64(file.Size)) + ") ")
+	return view
+}
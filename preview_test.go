@@ -0,0 +1,39 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreviewBytes(t *testing.T) {
+	conf := testConfig(t)
+
+	fakeCommand(t, "gdrive", "printf 'short file'\n")
+	got, err := previewBytes(conf, "id", 100)
+	if err != nil || string(got) != "short file" {
+		t.Errorf("previewBytes of a short file = %q, %v", got, err)
+	}
+
+	// gdrive never ends on its own, stopping it once there is enough isn't an error
+	fakeCommand(t, "gdrive", "yes\n")
+	got, err = previewBytes(conf, "id", 100)
+	if err != nil || len(got) != 100 {
+		t.Errorf("previewBytes of a long file = %d bytes, %v, want 100 bytes", len(got), err)
+	}
+}
+
+func TestPreviewBytesGdriveFails(t *testing.T) {
+	conf := testConfig(t)
+	fakeCommand(t, "gdrive", "echo 'Error: File not found: id' >&2\nexit 1\n")
+
+	got, err := previewBytes(conf, "id", 100)
+	if err == nil {
+		t.Fatalf("previewBytes should fail when gdrive does, got %q", got)
+	}
+	if !strings.Contains(err.Error(), "File not found: id") {
+		t.Errorf("the error should be what gdrive said, got %q", err)
+	}
+}
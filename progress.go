@@ -0,0 +1,56 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// progressEvent is one line of -progress-json. The fields are a stable interface for
+// other programs, add new ones but don't rename or remove them.
+//
+//	{"event":"folder","path":"/Photos","files":12,"folders":3,"done":5,"total":9}
+//	{"event":"done","path":"/Photos","folders":9,"calls":9,"seconds":4.2}
+//
+// folder is sent for every fetched folder with its direct children, done/total are the
+// progress of a deep scan (0 outside of one). done is sent when a deep scan finished.
+type progressEvent struct {
+	Event   string  `json:"event"`
+	Path    string  `json:"path"`
+	Files   int     `json:"files,omitempty"`
+	Folders int     `json:"folders"`
+	Done    int     `json:"done,omitempty"`
+	Total   int     `json:"total,omitempty"`
+	Calls   int64   `json:"calls,omitempty"`
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+// progress receives scan events, it does nothing unless -progress-json is set
+var progress = func(progressEvent) {}
+
+// openProgress points progress at the given file, or stdout for "-"
+func openProgress(path string) (io.Closer, error) {
+	var w io.WriteCloser = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	}
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	progress = func(e progressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := enc.Encode(e); err != nil {
+			log("failed to write progress: "+err.Error(), WARN)
+		}
+	}
+	return w, nil
+}
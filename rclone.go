@@ -0,0 +1,107 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// rcloneEntry is one item of rclone lsjson, only with the fields we need
+type rcloneEntry struct {
+	ID       string
+	Name     string
+	Size     int64
+	MimeType string
+	ModTime  time.Time
+	IsDir    bool
+}
+
+// remotePath is where the folder lives on the -remote, e.g. gdrive:Photos/Raw.
+// Unlike fullPath it uses the real names, rclone needs them to find the folder.
+func (f *Folder) remotePath(conf *Config) string {
+	names := []string{}
+	for cur := f; cur.parent != nil; cur = cur.parent {
+		names = append(names, cur.Name)
+	}
+	slices.Reverse(names)
+	res := conf.Remote
+	if len(names) > 0 && !strings.HasSuffix(res, ":") && !strings.HasSuffix(res, "/") {
+		res += "/"
+	}
+	return res + strings.Join(names, "/")
+}
+
+// parseRcloneList replaces the folder's children with what's in the output of rclone lsjson.
+// Not every remote has IDs, there the path on the remote identifies an entry.
+func (f *Folder) parseRcloneList(conf *Config, raw string) error {
+	var entries []rcloneEntry
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return errors.New("Unexpected format of rclone lsjson for folder " + f.fullPath() + ": " + err.Error())
+	}
+
+	// re-use folders we already know, so a refresh keeps their cached subtrees
+	cached := map[string]*Folder{}
+	for i := range f.Folders {
+		cached[f.Folders[i].ID] = f.Folders[i]
+	}
+
+	dir := f.remotePath(conf)
+	if !strings.HasSuffix(dir, ":") && !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	files := []*File{}
+	folders := []*Folder{}
+	skipped := map[string]int{}
+	for _, e := range entries {
+		id := e.ID
+		if id == "" {
+			id = dir + e.Name
+		}
+
+		if e.IsDir {
+			if folder, ok := cached[id]; ok {
+				folder.Name = e.Name
+				folder.Date = e.ModTime.Unix()
+				folders = append(folders, folder)
+				continue
+			}
+			folders = append(folders, &Folder{ID: id, Name: e.Name, Date: e.ModTime.Unix(), save: f.save})
+			continue
+		}
+
+		file := &File{
+			ID:       id,
+			Name:     e.Name,
+			Ext:      filepath.Ext(e.Name),
+			Date:     e.ModTime.Unix(),
+			MimeType: e.MimeType,
+		}
+		// Google Docs and the like have no size (-1), like documents in gdrive
+		if e.Size < 0 || strings.HasPrefix(e.MimeType, "application/vnd.google-apps.") {
+			file.Type = "document"
+		} else {
+			file.Size = int(e.Size)
+		}
+		if slices.Contains(conf.ExcludeTypes, file.Type) {
+			skipped[file.Type] += 1
+			continue
+		}
+		files = append(files, file)
+	}
+
+	f.Files = files
+	f.Streamed = false
+	f.own = nil
+	f.Folders = folders
+	f.Skipped = skipped
+	f.markScanned(conf)
+	f.markDirty()
+
+	return nil
+}
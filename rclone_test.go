@@ -0,0 +1,53 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyRemoteSubfolder(t *testing.T) {
+	conf := testConfig(t, "-remote", "r:")
+	// every call is written to calls, the listing is always empty
+	dir := fakeCommand(t, "rclone", "echo \"$@\" >> \"$(dirname \"$0\")/calls\"\necho '[]'\n")
+	b := &Folder{ID: "r:A/B", Name: "B", LastUpdate: time.Now().Unix()}
+	a := &Folder{ID: "r:A", Name: "A", LastUpdate: time.Now().Unix(), Folders: []*Folder{b}}
+	testTree(conf, &Folder{ID: "r:", LastUpdate: time.Now().Unix(), Folders: []*Folder{a}})
+
+	if _, err := verify(conf, b, 0, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(calls)); got != "lsjson r:A/B" {
+		t.Errorf("expected the folder to be listed by its path on the remote, got %q", got)
+	}
+}
+
+func TestRemoteDoesntCallGdrive(t *testing.T) {
+	conf := testConfig(t, "-remote", "r:")
+	// a gdrive that fails whenever it's called
+	fakeCommand(t, "gdrive", "exit 1\n")
+
+	if err := checkRoot(conf, &Folder{ID: "r:"}); err != nil {
+		t.Errorf("a remote can't be checked with gdrive: %v", err)
+	}
+	file := &File{ID: "r:a.txt", Name: "a.txt", Ext: ".txt", Size: 10}
+	if err := deleteEntry(conf, entry{file: file}); err == nil || !strings.Contains(err.Error(), "only works with gdrive") {
+		t.Errorf("expected deleting to be refused, got %v", err)
+	}
+	if err := downloadFile(conf, file, t.TempDir()); err == nil || !strings.Contains(err.Error(), "only works with gdrive") {
+		t.Errorf("expected downloading to be refused, got %v", err)
+	}
+	if _, err := preview(conf, file); err == nil || !strings.Contains(err.Error(), "only works with gdrive") {
+		t.Errorf("expected previewing to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+const recentCount = 100
+
+type pathFile struct {
+	path string
+	file *File
+}
+
+// recentFiles returns the n newest files of the tree, files without a date come last
+func recentFiles(conf *Config, root *Folder, n int) []pathFile {
+	res := []pathFile{}
+	root.walkFiles(conf, func(path string, file *File) {
+		res = append(res, pathFile{path: path, file: file})
+	})
+
+	sort.Slice(res, func(i, j int) bool {
+		a := res[i].file.Date
+		b := res[j].file.Date
+		if (a == 0) != (b == 0) {
+			return b == 0
+		}
+		if a != b {
+			return a > b
+		}
+		return res[i].path < res[j].path
+	})
+
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+func newRecentView(conf *Config, root *Folder) *tview.TextView {
+	files := recentFiles(conf, root, recentCount)
+
+	var sb strings.Builder
+	for _, pf := range files {
+		fmt.Fprintf(&sb, "%-16s %9s  %s\n", formatDate(pf.file.Date), conf.formatSize(int64(pf.file.Size)), pf.path)
+	}
+
+	view := tview.NewTextView().SetText(sb.String())
+	view.SetBorder(true).SetTitle(fmt.Sprintf(" %d most recent files ", len(files)))
+	return view
+}
+
+// formatDate shows a unix time to the minute, 0 is unknown
+func formatDate(unix int64) string {
+	if unix == 0 {
+		return "unknown"
+	}
+	return time.Unix(unix, 0).Format("2006-01-02 15:04")
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+type apiEntry struct {
+	Name    string `json:"name"`
+	Path    string `json:"pathName"` // Name as it appears in paths, e.g. with / replaced
+	Size    int64  `json:"size"`
+	Label   string `json:"sizeLabel"` // Size like the TUI shows it, in the units of -si
+	Folder  bool   `json:"folder"`
+	Unknown int    `json:"unknown,omitempty"`
+}
+
+type apiFolder struct {
+	Path    string     `json:"path"`
+	Size    int64      `json:"size"`
+	Label   string     `json:"sizeLabel"`
+	Entries []apiEntry `json:"entries"`
+}
+
+// serve exposes the cached tree via a small JSON API and a static treemap page.
+// It never talks to the backend, it is purely a view over what's in the cache.
+// Like the TUI and the exports, it leaves out what is ignored or filtered by date.
+func serve(conf *Config, root *Folder, addr string) error {
+	log("serving cached tree on http://"+addr, INFO)
+	return http.ListenAndServe(addr, newServeMux(conf, root))
+}
+
+func newServeMux(conf *Config, root *Folder) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/folder", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Query().Get("path")
+		f, ok := FindByPath(root, path)
+		if !ok || conf.Ignore.matches(f.fullPath(), true) {
+			http.Error(w, "folder not found: "+path, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(f.apiFolder(conf)); err != nil {
+			log("failed to write response: "+err.Error(), ERROR)
+		}
+	})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(serverPage))
+	})
+	return mux
+}
+
+func (f *Folder) apiFolder(conf *Config) apiFolder {
+	files := f.fileList(conf)
+	res := apiFolder{
+		Path:    f.fullPath(),
+		Size:    f.size,
+		Label:   f.sizeLabel(conf),
+		Entries: make([]apiEntry, 0, len(f.Folders)+len(files)),
+	}
+
+	for i := range f.Folders {
+		folder := f.Folders[i]
+		if conf.Ignore.matches(folder.fullPath(), true) {
+			continue
+		}
+		res.Entries = append(res.Entries, apiEntry{
+			Name:    folder.Name,
+			Path:    pathName(folder.Name),
+			Size:    folder.size,
+			Label:   folder.sizeLabel(conf),
+			Folder:  true,
+			Unknown: folder.unknown,
+		})
+	}
+	for i := range files {
+		file := files[i]
+		if conf.hidesFile(f.filePath(file), file) {
+			continue
+		}
+		res.Entries = append(res.Entries, apiEntry{
+			Name:  file.Name,
+			Path:  pathName(file.Name),
+			Size:  int64(file.Size),
+			Label: entry{file: file}.sizeLabel(conf),
+		})
+	}
+
+	sort.SliceStable(res.Entries, func(i, j int) bool {
+		a := res.Entries[i]
+		b := res.Entries[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Name < b.Name
+	})
+
+	return res
+}
+
+const serverPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ggdu</title>
+<style>
+  body { margin: 0; font-family: sans-serif; background: #222; color: #eee; }
+  header { padding: 8px; }
+  header a { color: #8af; cursor: pointer; }
+  #map { position: relative; height: calc(100vh - 40px); }
+  .cell { position: absolute; box-sizing: border-box; border: 1px solid #222; overflow: hidden;
+          font-size: 12px; padding: 2px; background: #456; }
+  .cell.folder { background: #357; cursor: pointer; }
+  .cell.folder:hover { background: #469; }
+</style>
+</head>
+<body>
+<header id="crumbs"></header>
+<div id="map"></div>
+<script>
+// squarified layout of entries (sorted by size desc) into the given rect
+function layout(entries, x, y, w, h, out) {
+  if (entries.length == 0) return;
+  const total = entries.reduce((s, e) => s + e.size, 0);
+  if (total <= 0) return;
+  const short = Math.min(w, h);
+  const scale = (w * h) / total;
+
+  const worst = (row, sum) => {
+    const side = sum * scale / short;
+    let max = 0;
+    for (const e of row) {
+      const len = e.size * scale / side;
+      max = Math.max(max, side / len, len / side);
+    }
+    return max;
+  };
+
+  let row = [], sum = 0, i = 0;
+  for (; i < entries.length; i++) {
+    const next = row.concat([entries[i]]);
+    if (row.length > 0 && worst(next, sum + entries[i].size) > worst(row, sum)) break;
+    row = next;
+    sum += entries[i].size;
+  }
+
+  const side = sum * scale / short;
+  let off = 0;
+  for (const e of row) {
+    const len = e.size * scale / side;
+    if (w >= h) out.push({ e: e, x: x, y: y + off, w: side, h: len });
+    else out.push({ e: e, x: x + off, y: y, w: len, h: side });
+    off += len;
+  }
+
+  if (w >= h) layout(entries.slice(i), x + side, y, w - side, h, out);
+  else layout(entries.slice(i), x, y + side, w, h - side, out);
+}
+
+function join(path, name) {
+  return (path.endsWith("/") ? path : path + "/") + name;
+}
+
+async function show(path) {
+  const res = await fetch("/api/folder?path=" + encodeURIComponent(path));
+  if (!res.ok) { alert(await res.text()); return; }
+  const data = await res.json();
+
+  const crumbs = document.getElementById("crumbs");
+  crumbs.innerHTML = "";
+  let acc = "/";
+  const root = document.createElement("a");
+  root.textContent = "/";
+  root.onclick = () => show("/");
+  crumbs.appendChild(root);
+  for (const part of data.path.split("/").filter(p => p)) {
+    acc = join(acc, part);
+    const target = acc;
+    const a = document.createElement("a");
+    a.textContent = part + "/";
+    a.onclick = () => show(target);
+    crumbs.appendChild(a);
+  }
+  crumbs.appendChild(document.createTextNode(" (" + data.sizeLabel + ")"));
+
+  const map = document.getElementById("map");
+  map.innerHTML = "";
+  const cells = [];
+  layout(data.entries.filter(e => e.size > 0), 0, 0, map.clientWidth, map.clientHeight, cells);
+  for (const c of cells) {
+    const div = document.createElement("div");
+    div.className = "cell" + (c.e.folder ? " folder" : "");
+    div.style.left = c.x + "px";
+    div.style.top = c.y + "px";
+    div.style.width = c.w + "px";
+    div.style.height = c.h + "px";
+    div.textContent = c.e.name + (c.e.folder ? "/" : "") + " " + c.e.sizeLabel;
+    div.title = div.textContent;
+    if (c.e.folder) div.onclick = () => show(join(data.path, c.e.pathName));
+    map.appendChild(div);
+  }
+}
+
+show("/");
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,78 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+// getFolder asks the API for the folder at path
+func getFolder(t *testing.T, mux http.Handler, path string) (int, apiFolder) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/folder?path="+url.QueryEscape(path), nil))
+	var res apiFolder
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, res
+}
+
+func TestServeFolderWithSlash(t *testing.T) {
+	conf := testConfig(t)
+	slash := &Folder{ID: "slash", Name: "a/b", Files: []*File{{ID: "f", Name: "x.txt", Size: 10}}}
+	root := testTree(conf, &Folder{Folders: []*Folder{slash}})
+	mux := newServeMux(conf, root)
+
+	code, top := getFolder(t, mux, "/")
+	if code != http.StatusOK || len(top.Entries) != 1 {
+		t.Fatalf("expected the root with one entry, got %d %+v", code, top)
+	}
+	e := top.Entries[0]
+	if e.Name != "a/b" || e.Path != "a_b" {
+		t.Errorf("expected the raw name to show and the path name to navigate, got %+v", e)
+	}
+
+	// that's how the page navigates into a folder
+	code, sub := getFolder(t, mux, "/"+e.Path)
+	if code != http.StatusOK || sub.Path != "/a_b" || sub.Size != 10 {
+		t.Errorf("expected to get into the folder by its path name, got %d %+v", code, sub)
+	}
+	if code, _ := getFolder(t, mux, "/"+e.Name); code != http.StatusNotFound {
+		t.Errorf("expected the raw name not to be a path, got %d", code)
+	}
+}
+
+func TestServeLikeTheTUI(t *testing.T) {
+	conf := testConfig(t, "-si", "-count-docs", "-exclude-path", "/skip", "-newer-than", "30d")
+	now := time.Now().Unix()
+	old := time.Now().AddDate(-1, 0, 0).Unix()
+	skip := &Folder{ID: "skip", Name: "skip", Files: []*File{{ID: "s", Name: "s.txt", Size: 500, Date: now}}}
+	keep := &Folder{ID: "keep", Name: "keep", Files: []*File{{ID: "k", Name: "k.txt", Size: 1000, Date: now}}}
+	root := testTree(conf, &Folder{Folders: []*Folder{skip, keep}, Files: []*File{
+		{ID: "o", Name: "old.txt", Size: 700, Date: old},
+		{ID: "d", Name: "doc", Type: "document", DocSize: 300, Date: now},
+	}})
+	mux := newServeMux(conf, root)
+
+	_, top := getFolder(t, mux, "/")
+	want := []apiEntry{
+		{Name: "keep", Path: "keep", Size: 1000, Label: "1.0kb", Folder: true},
+		{Name: "doc", Path: "doc", Size: 300, Label: "300b"},
+	}
+	if !slices.Equal(top.Entries, want) {
+		t.Errorf("expected only what the TUI lists, in -si units\n got: %+v\nwant: %+v", top.Entries, want)
+	}
+	if code, _ := getFolder(t, mux, "/skip"); code != http.StatusNotFound {
+		t.Errorf("expected the excluded folder not to be served, got %d", code)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+)
+
+// State keeps UI preferences between runs. It lives next to the cache but is
+// kept separate, so it survives throwing the cache away.
+type State struct {
+	// sort order chosen for individual folders, by full path
+	FolderSort map[string]string `json:",omitempty"`
+	// full paths of bookmarked folders
+	Bookmarks []string `json:",omitempty"`
+	// the last -bar-style that was chosen
+	BarStyle string `json:",omitempty"`
+
+	path string
+}
+
+func statePath(conf *Config) string {
+	return strings.TrimSuffix(conf.SavePath, ".json") + ".state.json"
+}
+
+func loadState(conf *Config) (*State, error) {
+	res := State{path: statePath(conf)}
+	raw, err := os.ReadFile(res.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, errors.New("failed to parse state " + res.path + ": " + err.Error())
+	}
+	return &res, nil
+}
+
+func (s *State) save() error {
+	res, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, res, 0644)
+}
+
+// sortFor returns the sort order for the folder at path, falling back to the global default
+func (s *State) sortFor(conf *Config, path string) string {
+	if by, ok := s.FolderSort[path]; ok {
+		return by
+	}
+	return conf.Sort
+}
+
+func (s *State) setSort(conf *Config, path string, by string) {
+	if by == conf.Sort {
+		delete(s.FolderSort, path)
+		return
+	}
+	if s.FolderSort == nil {
+		s.FolderSort = map[string]string{}
+	}
+	s.FolderSort[path] = by
+}
+
+// useBarStyle remembers a chosen -bar-style for the next runs, or picks up the remembered one if none was chosen
+func (s *State) useBarStyle(conf *Config) error {
+	if conf.BarStyle == "" {
+		conf.BarStyle = s.BarStyle
+		return nil
+	}
+	if conf.BarStyle == s.BarStyle {
+		return nil
+	}
+	s.BarStyle = conf.BarStyle
+	return s.save()
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBarStyleIsRemembered(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), "db.json")
+	// what startApp does with the bar style of a run with these args
+	run := func(args ...string) *Config {
+		t.Helper()
+		conf, err := parseConfig(append([]string{"-cache", cache}, args...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := loadState(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.useBarStyle(conf); err != nil {
+			t.Fatal(err)
+		}
+		return conf
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "blocks"},
+		{[]string{"-bar-style", "dots"}, "dots"},
+		{nil, "dots"},
+		// -ascii-bar is only for this run
+		{[]string{"-ascii-bar"}, "ascii"},
+		{nil, "dots"},
+		{[]string{"-bar-style", "arrows"}, "arrows"},
+		{nil, "arrows"},
+	}
+	for i, tt := range tests {
+		if got := run(tt.args...).barRunes(); !slices.Equal(got, barStyles[tt.want]) {
+			t.Errorf("run %d with %v: expected %s, got %q", i, tt.args, tt.want, string(got))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// fileStats describes the files directly inside a folder that the explorer lists.
+// Google Docs are left out, their size is 0 or only an estimate.
+type fileStats struct {
+	count   int
+	average int64
+	median  int64
+	largest *File
+}
+
+func (f *Folder) fileStats(conf *Config) fileStats {
+	res := fileStats{}
+	sizes := []int64{}
+	var total int64
+	files := f.fileList(conf)
+	for i := range files {
+		file := files[i]
+		if file.Type == "document" || conf.hidesFile(f.filePath(file), file) {
+			continue
+		}
+		sizes = append(sizes, int64(file.Size))
+		total += int64(file.Size)
+		if res.largest == nil || file.Size > res.largest.Size {
+			res.largest = file
+		}
+	}
+	res.count = len(sizes)
+	if res.count == 0 {
+		return res
+	}
+
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	mid := len(sizes) / 2
+	if len(sizes)%2 == 0 {
+		res.median = (sizes[mid-1] + sizes[mid]) / 2
+	} else {
+		res.median = sizes[mid]
+	}
+	res.average = total / int64(res.count)
+
+	return res
+}
+
+func (s fileStats) describe(conf *Config) string {
+	if s.count == 0 {
+		return "no files"
+	}
+	return fmt.Sprintf("%d files, avg %s, median %s, largest %s (%s)",
+		s.count, conf.formatSize(s.average), conf.formatSize(s.median), s.largest.Name, conf.formatSize(int64(s.largest.Size)))
+}
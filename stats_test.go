@@ -0,0 +1,37 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStats(t *testing.T) {
+	ignore := filepath.Join(t.TempDir(), "ignore")
+	if err := os.WriteFile(ignore, []byte("*.iso\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf := testConfig(t, "-estimate-doc-size", "-ignore-file", ignore, "-newer-than", "30d")
+	now := time.Now().Unix()
+	old := time.Now().AddDate(-1, 0, 0).Unix()
+	root := testTree(conf, &Folder{Files: []*File{
+		{ID: "a", Name: "a.txt", Size: 10, Date: now},
+		{ID: "b", Name: "b.txt", Size: 20, Date: now},
+		{ID: "c", Name: "c.txt", Size: 60, Date: now},
+		{ID: "iso", Name: "big.iso", Size: 1000, Date: now},
+		{ID: "old", Name: "old.txt", Size: 500, Date: old},
+		{ID: "doc", Name: "doc", Type: "document", Date: now},
+	}})
+
+	got := root.fileStats(conf)
+	if got.count != 3 || got.average != 30 || got.median != 20 || got.largest.ID != "c" {
+		t.Errorf("expected only what the explorer lists without documents, got %+v", got)
+	}
+	if got := (&Folder{}).fileStats(conf).describe(conf); got != "no files" {
+		t.Errorf("unexpected stats of an empty folder: %q", got)
+	}
+}
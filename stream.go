@@ -0,0 +1,179 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+)
+
+// streamStore keeps the files of folders in a JSON lines file next to the cache, for -stream-to jsonl.
+// Deep scans write the files of every folder there as they go instead of keeping them in memory,
+// and they are read back one folder at a time whenever they are needed.
+// Each line is one folder. Fetching it again appends a new line, the last one of a folder wins.
+type streamStore struct {
+	path    string
+	offsets map[string]int64 // of the last line of every folder, nil until the file was read
+}
+
+type streamLine struct {
+	ID    string
+	Files []*File
+}
+
+// streamPath is where the stream store of the cache at savePath lives
+func streamPath(savePath string) string {
+	return savePath + ".files.jsonl"
+}
+
+// index finds the last line of every folder, it only reads the file once
+func (s *streamStore) index() error {
+	if s.offsets != nil {
+		return nil
+	}
+	offsets := map[string]int64{}
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.offsets = offsets
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var offset int64
+	for {
+		raw, err := r.ReadBytes('\n')
+		// a partial line is from a write that didn't finish, the folder's previous line still counts
+		var line struct{ ID string }
+		if len(raw) > 0 && json.Unmarshal(raw, &line) == nil {
+			offsets[line.ID] = offset
+		} else if len(raw) > 0 {
+			log("skipping a partial line in "+s.path, WARN)
+		}
+		offset += int64(len(raw))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.offsets = offsets
+	return nil
+}
+
+// put appends the files of a folder, they replace whatever the store had for it
+func (s *streamStore) put(id string, files []*File) error {
+	if err := s.index(); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(streamLine{ID: id, Files: files})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	// the new line mustn't be glued to a partial one that is left from a write that didn't finish
+	if offset > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, offset-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			raw = append([]byte{'\n'}, raw...)
+			offset += 1
+		}
+	}
+	if _, err := file.Write(append(raw, '\n')); err != nil {
+		return err
+	}
+	s.offsets[id] = offset
+	return nil
+}
+
+// get reads the files of a folder
+func (s *streamStore) get(id string) ([]*File, error) {
+	if err := s.index(); err != nil {
+		return nil, err
+	}
+	offset, ok := s.offsets[id]
+	if !ok {
+		return nil, errors.New("no files of " + id + " in " + s.path)
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var line streamLine
+	if err := json.NewDecoder(file).Decode(&line); err != nil {
+		return nil, errors.New("corrupt line in " + s.path + ": " + err.Error())
+	}
+	return line.Files, nil
+}
+
+// store is the stream store of the tree the folder is in, nil if there is none
+func (f *Folder) store() *streamStore {
+	root := f
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root.stream
+}
+
+// fileList is the files directly in the folder, from the stream store if they were streamed there
+func (f *Folder) fileList(conf *Config) []*File {
+	if !f.Streamed {
+		return f.Files
+	}
+	store := f.store()
+	if store == nil {
+		log("no stream store for the files of "+f.fullPath(), ERROR)
+		return nil
+	}
+	files, err := store.get(f.ID)
+	if err != nil {
+		log("failed to read the files of "+f.fullPath()+": "+err.Error(), ERROR)
+		return nil
+	}
+	// like in rebuild, e.g. documents get their size
+	sumFiles(conf, files)
+	return files
+}
+
+// streamOut moves the files of a freshly fetched folder into the stream store. What they add
+// to the folder's sizes is kept, so they don't have to be read again for every rebuild.
+func (f *Folder) streamOut(conf *Config) error {
+	store := f.store()
+	if store == nil {
+		return errors.New("no stream store for " + f.fullPath())
+	}
+	if err := store.put(f.ID, f.Files); err != nil {
+		return errors.New("failed to stream the files of " + f.fullPath() + ": " + err.Error())
+	}
+	own := sumFiles(conf, f.Files)
+	f.own = &own
+	f.Files = nil
+	f.Streamed = true
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// streamedTree is a deep scan of root (a file and folder a) with a (two files) streamed to disk
+func streamedTree(t *testing.T) (*Config, *Folder, string) {
+	t.Helper()
+	conf := testConfig(t, "-stream-to", "jsonl")
+	dir := fakeGdrive(t, map[string]string{
+		"root": gdriveList("\n",
+			row("r", "r.txt", "regular", "5", "2024-01-02 03:04:05"),
+			row("a", "a", "folder", "", "2024-01-02 03:04:05"),
+		),
+		"a": gdriveList("\n",
+			row("a1", "a1.txt", "regular", "10", "2024-01-02 03:04:05"),
+			row("a2", "a2.txt", "regular", "20", "2024-01-02 03:04:05"),
+		),
+	})
+	root := testTree(conf, &Folder{})
+	if err := deepScan(conf, root); err != nil {
+		t.Fatal(err)
+	}
+	return conf, root, dir
+}
+
+func TestStreamDeepScan(t *testing.T) {
+	conf, root, _ := streamedTree(t)
+	a := root.Folders[0]
+	for _, f := range []*Folder{root, a} {
+		if f.Files != nil || !f.Streamed {
+			t.Errorf("expected the files of %s to be streamed, got %d in memory", f.fullPath(), len(f.Files))
+		}
+	}
+	if root.size != 35 || root.files != 3 || a.size != 30 {
+		t.Errorf("expected 35 bytes in 3 files and 30 bytes in a, got %d bytes in %d files and %d bytes in a", root.size, root.files, a.size)
+	}
+	if files := a.fileList(conf); len(files) != 2 || files[0].ID != "a1" || files[0].Ext != ".txt" {
+		t.Errorf("expected a's files to be read back from the store, got %+v", files)
+	}
+	checkAggregates(t, conf, root)
+}
+
+func TestStreamSaveLoad(t *testing.T) {
+	conf, root, _ := streamedTree(t)
+	if err := save(conf, root); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := load(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := aggregatesOf(loaded), aggregatesOf(root); got != want {
+		t.Errorf("expected the loaded tree to add up like the scanned one:\n got: %+v\nwant: %+v", got, want)
+	}
+	if files := loaded.Folders[0].fileList(conf); len(files) != 2 {
+		t.Errorf("expected a's 2 files after loading, got %+v", files)
+	}
+}
+
+func TestStreamRefetch(t *testing.T) {
+	conf, root, dir := streamedTree(t)
+	a := root.Folders[0]
+	raw := gdriveList("\n", row("a3", "a3.txt", "regular", "7", "2024-01-02 03:04:05"))
+	if err := os.WriteFile(filepath.Join(dir, "a.list"), []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ensureData(conf, true, &goDeep{max: 1, onUpdate: func(*Folder) {}}); err != nil {
+		t.Fatal(err)
+	}
+	if root.size != 12 {
+		t.Errorf("expected 12 bytes after a was fetched again, got %d", root.size)
+	}
+	checkAggregates(t, conf, root)
+
+	// the store only grows, a fresh read has to find the last line of a
+	store := &streamStore{path: streamPath(conf.SavePath)}
+	if files, err := store.get("a"); err != nil || len(files) != 1 || files[0].ID != "a3" {
+		t.Errorf("expected only a3 in a, got %+v, %v", files, err)
+	}
+}
+
+func TestStreamRemove(t *testing.T) {
+	conf, root, _ := streamedTree(t)
+	a := root.Folders[0]
+	a.remove("a1")
+	root.rebuild(conf)
+	if root.size != 25 || root.files != 2 {
+		t.Errorf("expected 25 bytes in 2 files, got %d bytes in %d files", root.size, root.files)
+	}
+	if files := a.fileList(conf); len(files) != 1 || files[0].ID != "a2" {
+		t.Errorf("expected only a2 to be left in a, got %+v", files)
+	}
+}
+
+func TestStreamPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json.files.jsonl")
+	if err := os.WriteFile(path, []byte(`{"ID":"a","Files":[]}`+"\n"+`{"ID":"a","Fi`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logged := captureLog(t)
+	store := &streamStore{path: path}
+	if files, err := store.get("a"); err != nil || len(files) != 0 {
+		t.Errorf("expected the complete line of a, got %+v, %v", files, err)
+	}
+	if len(*logged) != 1 {
+		t.Errorf("expected a warning about the partial line, got %q", *logged)
+	}
+
+	if err := store.put("b", []*File{{ID: "b1"}}); err != nil {
+		t.Fatal(err)
+	}
+	fresh := &streamStore{path: path}
+	if files, err := fresh.get("b"); err != nil || len(files) != 1 || files[0].ID != "b1" {
+		t.Errorf("expected b's line not to be glued to the partial one, got %+v, %v", files, err)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// writeSummary prints the root's direct children with their share of it, largest first,
+// like the explorer shows them on startup
+func writeSummary(conf *Config, root *Folder, w io.Writer) error {
+	files := root.fileList(conf)
+	entries := make([]entry, 0, len(root.Folders)+len(files))
+	for i := range root.Folders {
+		if !conf.Ignore.matches(root.Folders[i].fullPath(), true) {
+			entries = append(entries, entry{folder: root.Folders[i]})
+		}
+	}
+	for i := range files {
+		if !conf.hidesFile(root.filePath(files[i]), files[i]) {
+			entries = append(entries, entry{file: files[i]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a := entries[i]
+		b := entries[j]
+		return less("size", a.name(), b.name(), a.size(), b.size(), a.date(), b.date())
+	})
+
+	if _, err := fmt.Fprintf(w, "%9s %6s %s\n", root.sizeLabel(conf), "", root.fullPath()); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		var pct float64
+		if root.size > 0 {
+			pct = float64(e.size()) / float64(root.size) * 100
+		}
+		name := e.name()
+		if e.folder != nil {
+			name += "/"
+		}
+		if _, err := fmt.Fprintf(w, "%9s %5.1f%% %s\n", e.sizeLabel(conf), pct, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsTerminal(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("a buffer is no terminal")
+	}
+	if isTerminal(file) {
+		t.Error("a regular file is no terminal")
+	}
+}
+
+func TestSummaryWithoutTerminal(t *testing.T) {
+	conf := testConfig(t)
+	photos := &Folder{ID: "photos", Name: "Photos", LastUpdate: time.Now().Unix(), Files: []*File{{ID: "p", Name: "p.jpg", Size: 3000}}}
+	root := &Folder{Folders: []*Folder{photos}, Files: []*File{{ID: "n", Name: "notes.txt", Size: 1000}}}
+	root.path = "/"
+
+	var out bytes.Buffer
+	printed, err := summaryWithoutTerminal(conf, root, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !printed {
+		t.Fatal("expected a summary instead of the TUI")
+	}
+	want := "    3.9kb        /\n    2.9kb  75.0% Photos/\n    1000b  25.0% notes.txt\n"
+	if got := out.String(); got != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+const (
+	svgWidth  = 1600
+	svgHeight = 1000
+	svgHeader = 16 // space for a folder's label above its children
+	svgPad    = 2
+)
+
+var svgColors = []string{"#2b4c6f", "#35648f", "#4a7fae", "#6a9bc6", "#8db6d9", "#b3d0e8"}
+
+// writeSVG renders the tree as a nested treemap, down to conf.Depth levels of folders
+func writeSVG(conf *Config, root *Folder, w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`+"\n", svgWidth, svgHeight)
+	if err != nil {
+		return err
+	}
+
+	entry := treemapEntry{name: root.fullPath(), size: root.size, folder: root}
+	if err := svgRect(conf, w, entry, 0, 0, svgWidth, svgHeight, 0); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+func svgRect(conf *Config, w io.Writer, e treemapEntry, x, y, width, height float64, depth int) error {
+	if width < 1 || height < 1 {
+		return nil
+	}
+
+	color := svgColors[min(depth, len(svgColors)-1)]
+	if e.folder == nil {
+		color = "#c9a227"
+	}
+	label := e.name + " " + conf.formatSize(e.size)
+	_, err := fmt.Fprintf(w, `<g><title>%s</title><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="#111"/>`,
+		html.EscapeString(label), x, y, width, height, color)
+	if err != nil {
+		return err
+	}
+	// only label what has room for at least a few characters, roughly 6px each
+	if chars := int(width-6) / 6; chars > 3 && height > 12 {
+		text := []rune(label)
+		if len(text) > chars {
+			text = append(text[:chars-1], '…')
+		}
+		_, err = fmt.Fprintf(w, `<text x="%.1f" y="%.1f" fill="#fff">%s</text>`, x+3, y+12, html.EscapeString(string(text)))
+		if err != nil {
+			return err
+		}
+	}
+	if _, err = fmt.Fprintln(w, "</g>"); err != nil {
+		return err
+	}
+
+	if e.folder == nil || depth >= conf.Depth {
+		return nil
+	}
+
+	ix := x + svgPad
+	iy := y + svgHeader
+	iw := width - 2*svgPad
+	ih := height - svgHeader - svgPad
+	if iw < 1 || ih < 1 {
+		return nil
+	}
+	for _, rect := range squarify(e.folder.treemapEntries(conf), ix, iy, iw, ih) {
+		if err := svgRect(conf, w, rect.entry, rect.x, rect.y, rect.w, rect.h, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// writeTree prints the cached folders like the tree command, largest first
+func writeTree(conf *Config, root *Folder, w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%9s %s\n", root.sizeLabel(conf), root.fullPath()); err != nil {
+		return err
+	}
+
+	var walk func(f *Folder, indent string) error
+	walk = func(f *Folder, indent string) error {
+		children := make([]*Folder, 0, len(f.Folders))
+		for i := range f.Folders {
+			if !conf.Ignore.matches(f.Folders[i].fullPath(), true) {
+				children = append(children, f.Folders[i])
+			}
+		}
+		sort.Slice(children, func(i, j int) bool {
+			a := children[i]
+			b := children[j]
+			return less("size", a.Name, b.Name, a.size, b.size, a.Date, b.Date)
+		})
+
+		for i, child := range children {
+			branch, next := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, next = "└── ", "    "
+			}
+			if _, err := fmt.Fprintf(w, "%9s %s%s/\n", child.sizeLabel(conf), indent+branch, child.Name); err != nil {
+				return err
+			}
+			if err := walk(child, indent+next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(root, "")
+}
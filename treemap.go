@@ -0,0 +1,198 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type treemapEntry struct {
+	name   string
+	size   int64
+	folder *Folder // nil for files
+}
+
+type treemapRect struct {
+	entry      treemapEntry
+	x, y, w, h float64
+}
+
+func (f *Folder) treemapEntries(conf *Config) []treemapEntry {
+	res := []treemapEntry{}
+	for i := range f.Folders {
+		folder := f.Folders[i]
+		if folder.size > 0 && !conf.Ignore.matches(folder.fullPath(), true) {
+			res = append(res, treemapEntry{name: folder.Name + "/", size: folder.size, folder: folder})
+		}
+	}
+	files := f.fileList(conf)
+	for i := range files {
+		file := files[i]
+		if file.Size > 0 && !conf.hidesFile(f.filePath(file), file) {
+			res = append(res, treemapEntry{name: file.Name, size: int64(file.Size)})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].size != res[j].size {
+			return res[i].size > res[j].size
+		}
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+// squarify lays out entries (sorted by size, descending) into the given rect,
+// trying to keep every cell as close to a square as possible
+func squarify(entries []treemapEntry, x, y, w, h float64) []treemapRect {
+	res := []treemapRect{}
+	var total int64
+	for i := range entries {
+		total += entries[i].size
+	}
+	if total <= 0 || w <= 0 || h <= 0 {
+		return res
+	}
+	scale := w * h / float64(total)
+
+	for len(entries) > 0 {
+		short := min(w, h)
+		worst := func(row []treemapEntry, sum int64) float64 {
+			side := float64(sum) * scale / short
+			var max float64
+			for i := range row {
+				l := float64(row[i].size) * scale / side
+				max = math.Max(max, math.Max(side/l, l/side))
+			}
+			return max
+		}
+
+		n := 1
+		sum := entries[0].size
+		for ; n < len(entries); n++ {
+			next := sum + entries[n].size
+			if worst(entries[:n+1], next) > worst(entries[:n], sum) {
+				break
+			}
+			sum = next
+		}
+
+		side := float64(sum) * scale / short
+		var off float64
+		for i := 0; i < n; i++ {
+			l := float64(entries[i].size) * scale / side
+			if w >= h {
+				res = append(res, treemapRect{entry: entries[i], x: x, y: y + off, w: side, h: l})
+			} else {
+				res = append(res, treemapRect{entry: entries[i], x: x + off, y: y, w: l, h: side})
+			}
+			off += l
+		}
+
+		entries = entries[n:]
+		if w >= h {
+			x += side
+			w -= side
+		} else {
+			y += side
+			h -= side
+		}
+	}
+
+	return res
+}
+
+// treemap renders the entries into a width x height grid of box-drawing characters
+func treemap(conf *Config, entries []treemapEntry, width, height int) [][]rune {
+	res := make([][]rune, height)
+	for i := range res {
+		res[i] = make([]rune, width)
+		for j := range res[i] {
+			res[i][j] = ' '
+		}
+	}
+
+	set := func(x, y int, r rune) {
+		if y >= 0 && y < height && x >= 0 && x < width {
+			res[y][x] = r
+		}
+	}
+
+	for _, rect := range squarify(entries, 0, 0, float64(width), float64(height)) {
+		x0 := int(math.Round(rect.x))
+		y0 := int(math.Round(rect.y))
+		x1 := int(math.Round(rect.x+rect.w)) - 1
+		y1 := int(math.Round(rect.y+rect.h)) - 1
+		if x1 < x0 || y1 < y0 {
+			continue
+		}
+
+		if x1-x0 < 1 || y1-y0 < 1 {
+			for y := y0; y <= y1; y++ {
+				for x := x0; x <= x1; x++ {
+					set(x, y, '▒')
+				}
+			}
+			continue
+		}
+
+		for x := x0 + 1; x < x1; x++ {
+			set(x, y0, '─')
+			set(x, y1, '─')
+		}
+		for y := y0 + 1; y < y1; y++ {
+			set(x0, y, '│')
+			set(x1, y, '│')
+		}
+		set(x0, y0, '┌')
+		set(x1, y0, '┐')
+		set(x0, y1, '└')
+		set(x1, y1, '┘')
+
+		inner := x1 - x0 - 1
+		lines := []string{rect.entry.name, conf.formatSize(rect.entry.size)}
+		for i, line := range lines {
+			if y0+1+i >= y1 {
+				break
+			}
+			runes := []rune(line)
+			if len(runes) > inner {
+				runes = runes[:inner]
+			}
+			for j, r := range runes {
+				set(x0+1+j, y0+1+i, r)
+			}
+		}
+	}
+
+	return res
+}
+
+// newTreemapView draws the treemap of whatever folder cur returns, sized to the available space
+func newTreemapView(conf *Config, cur func() *Folder) *tview.Box {
+	box := tview.NewBox().SetBorder(true)
+	box.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		f := cur()
+		title := " " + tview.Escape(f.fullPath()) + " (" + conf.formatSize(f.size) + ") "
+		tview.Print(screen, title, x+1, y, width-2, tview.AlignCenter, conf.color(tcell.ColorWhite))
+		ix, iy, iw, ih := x+1, y+1, width-2, height-2
+		if iw <= 0 || ih <= 0 {
+			return ix, iy, iw, ih
+		}
+
+		grid := treemap(conf, f.treemapEntries(conf), iw, ih)
+		style := tcell.StyleDefault.Foreground(conf.color(tcell.ColorOrange))
+		for row := range grid {
+			for col, r := range grid[row] {
+				screen.SetContent(ix+col, iy+row, r, nil, style)
+			}
+		}
+		return ix, iy, iw, ih
+	})
+	return box
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// newFolderTreeView shows the cached tree as an expandable tree, as an alternative to
+// drilling in and out of folders. Children are only added once a folder is expanded.
+func newFolderTreeView(conf *Config, root *Folder) *tview.TreeView {
+	rootNode := newFolderNode(conf, root, root.fullPath())
+	addChildNodes(conf, rootNode, root)
+	rootNode.SetExpanded(true)
+
+	tree := tview.NewTreeView().SetRoot(rootNode).SetCurrentNode(rootNode)
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		f, ok := node.GetReference().(*Folder)
+		if !ok {
+			return
+		}
+		if len(node.GetChildren()) == 0 {
+			addChildNodes(conf, node, f)
+			node.SetExpanded(true)
+			return
+		}
+		node.SetExpanded(!node.IsExpanded())
+	})
+	tree.SetBorder(true).SetTitle(" tree (enter expands and collapses folders) ")
+	return tree
+}
+
+func newFolderNode(conf *Config, f *Folder, name string) *tview.TreeNode {
+	// folders that were never scanned are marked like in the explorer
+	scanned := " "
+	if f.LastUpdate == 0 {
+		scanned = "▸"
+	}
+	return tview.NewTreeNode(fmt.Sprintf("%9s %s%s", f.sizeLabel(conf), scanned, name)).
+		SetReference(f).
+		SetColor(conf.color(tcell.ColorBlue))
+}
+
+// addChildNodes adds the folder's entries to its node, largest first
+func addChildNodes(conf *Config, node *tview.TreeNode, f *Folder) {
+	files := f.fileList(conf)
+	entries := make([]entry, 0, len(f.Folders)+len(files))
+	for i := range f.Folders {
+		if !conf.Ignore.matches(f.Folders[i].fullPath(), true) {
+			entries = append(entries, entry{folder: f.Folders[i]})
+		}
+	}
+	for i := range files {
+		if !conf.hidesFile(f.filePath(files[i]), files[i]) {
+			entries = append(entries, entry{file: files[i]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a := entries[i]
+		b := entries[j]
+		return less("size", a.name(), b.name(), a.size(), b.size(), a.date(), b.date())
+	})
+
+	for _, e := range entries {
+		if e.folder != nil {
+			node.AddChild(newFolderNode(conf, e.folder, e.folder.Name+"/"))
+			continue
+		}
+		node.AddChild(tview.NewTreeNode(fmt.Sprintf("%9s  %s", e.sizeLabel(conf), e.name())).
+			SetSelectable(false))
+	}
+}
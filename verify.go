@@ -0,0 +1,142 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+type mismatch struct {
+	path   string
+	cached int64
+	fresh  int64
+}
+
+// verify re-fetches folders from the backend and compares their aggregate sizes with
+// the cache. With sample > 0 only that many random folders (and their subtrees) are
+// checked, otherwise the whole tree is. Nothing in the cache is changed.
+func verify(conf *Config, root *Folder, sample int, w io.Writer) ([]mismatch, error) {
+	roots := []*Folder{root}
+	if sample > 0 {
+		all := []*Folder{}
+		queue := []*Folder{root}
+		for i := 0; i < len(queue); i++ {
+			cur := queue[i]
+			if cur.LastUpdate != 0 {
+				all = append(all, cur)
+			}
+			queue = append(queue, cur.Folders...)
+		}
+		rand.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+		roots = all[:min(sample, len(all))]
+	}
+
+	res := []mismatch{}
+	for _, cached := range roots {
+		fresh := detachedCopy(cached)
+		log("verify "+cached.fullPath(), INFO)
+		deep := &goDeep{max: 1, onUpdate: func(*Folder) {}}
+		if err := fresh.ensureData(conf, true, deep); err != nil {
+			return res, err
+		}
+		res = append(res, compareTrees(conf, cached, fresh)...)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].path < res[j].path })
+	res = dedupeMismatches(res)
+
+	fmt.Fprintf(w, "verified %d folder(s), %d mismatch(es)\n", len(roots), len(res))
+	for _, m := range res {
+		fmt.Fprintf(w, "%10s %10s %+11s  %s\n", conf.formatSize(m.cached), conf.formatSize(m.fresh), conf.formatDelta(m.fresh-m.cached), m.path)
+	}
+	return res, nil
+}
+
+// detachedCopy is an empty folder in the same place as f, with copies of its parents, so
+// it is fetched like f (e.g. by its path on a -remote) without touching the cache
+func detachedCopy(f *Folder) *Folder {
+	res := &Folder{
+		ID:      f.ID,
+		Name:    f.Name,
+		path:    f.path,
+		save:    func() error { return nil },
+		virtual: f.virtual,
+		stream:  f.stream,
+	}
+	if f.parent != nil {
+		res.parent = detachedCopy(f.parent)
+	}
+	return res
+}
+
+// compareTrees reports every folder of the fresh tree whose size differs from the cached one.
+// Folders are matched by ID, folders missing on either side count as 0.
+// Ignored folders aren't scanned, so they are left out and don't count towards their parents.
+func compareTrees(conf *Config, cached, fresh *Folder) []mismatch {
+	res := []mismatch{}
+	var cachedSize int64
+	if cached != nil {
+		cachedSize = cached.size - ignoredSize(conf, cached)
+	}
+	if cachedSize != fresh.size {
+		res = append(res, mismatch{path: fresh.fullPath(), cached: cachedSize, fresh: fresh.size})
+	}
+
+	known := map[string]*Folder{}
+	if cached != nil {
+		for i := range cached.Folders {
+			known[cached.Folders[i].ID] = cached.Folders[i]
+		}
+	}
+	for i := range fresh.Folders {
+		child := fresh.Folders[i]
+		before := known[child.ID]
+		delete(known, child.ID)
+		if conf.Ignore.matches(child.fullPath(), true) {
+			continue
+		}
+		res = append(res, compareTrees(conf, before, child)...)
+	}
+	for _, gone := range known {
+		if gone.size != 0 && !conf.Ignore.matches(gone.fullPath(), true) {
+			res = append(res, mismatch{path: gone.fullPath(), cached: gone.size - ignoredSize(conf, gone)})
+		}
+	}
+	return res
+}
+
+// ignoredSize is how much of the cached size of f is in ignored folders below it
+func ignoredSize(conf *Config, f *Folder) int64 {
+	var res int64
+	for _, child := range f.Folders {
+		if conf.Ignore.matches(child.fullPath(), true) {
+			res += child.size
+		} else {
+			res += ignoredSize(conf, child)
+		}
+	}
+	return res
+}
+
+// dedupeMismatches drops repeated reports of the same path, which happen when sampled subtrees overlap
+func dedupeMismatches(ms []mismatch) []mismatch {
+	res := ms[:0]
+	for i := range ms {
+		if i > 0 && ms[i].path == ms[i-1].path {
+			continue
+		}
+		res = append(res, ms[i])
+	}
+	return res
+}
+
+func (c *Config) formatDelta(d int64) string {
+	if d < 0 {
+		return "-" + c.formatSize(-d)
+	}
+	return "+" + c.formatSize(d)
+}
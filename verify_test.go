@@ -0,0 +1,43 @@
+// Copyright 2026 Christian Dominik Richter
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestVerifyIgnoresExcludedFolders(t *testing.T) {
+	conf := testConfig(t, "-exclude-path", "/skip")
+	fakeGdrive(t, map[string]string{
+		"root": gdriveList("\n",
+			row("f", "f.txt", "regular", "100", "2024-01-02 03:04:05"),
+			row("skip", "skip", "folder", "", "2024-01-02 03:04:05"),
+		),
+	})
+	now := time.Now().Unix()
+	skip := &Folder{ID: "skip", Name: "skip", LastUpdate: now, Files: []*File{{ID: "s", Name: "s.txt", Size: 500}}}
+	root := testTree(conf, &Folder{ID: "root", LastUpdate: now, Folders: []*Folder{skip},
+		Files: []*File{{ID: "f", Name: "f.txt", Size: 100}}})
+
+	mismatches, err := verify(conf, root, 0, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("nothing drifted, but verify reported %+v", mismatches)
+	}
+
+	// the excluded folder still doesn't hide real drift next to it
+	root.Files[0].Size = 90
+	root.rebuild(conf)
+	mismatches, err = verify(conf, root, 0, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || mismatches[0] != (mismatch{path: "/", cached: 90, fresh: 100}) {
+		t.Errorf("expected / to be off by 10b, got %+v", mismatches)
+	}
+}